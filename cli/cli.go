@@ -0,0 +1,439 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-reader/config"
+	"go-reader/dao"
+	"go-reader/views"
+)
+
+// 退出码
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+type command struct {
+	name   string
+	usage  string
+	run    func(args []string) error
+	needDB bool
+}
+
+// errUsage 参数错误，以ExitUsage退出
+var errUsage = errors.New("usage")
+
+var stdout io.Writer = os.Stdout
+var stderr io.Writer = os.Stderr
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"import", "import [--encoding NAME] [--convert s2t|t2s] <files...>", runImport, true},
+		{"list", "list [--json]", runList, true},
+		{"remove", "remove <title>", runRemove, true},
+		{"info", "info <title> [--json]", runInfo, true},
+		{"toc", "toc <title>", runToc, true},
+		{"cat", "cat <title> [--chapter N]", runCat, true},
+		{"progress", "progress <title> [--set 42%]", runProgress, true},
+		{"export", "export <title> [--format epub|md] [-o FILE]", runExport, true},
+		{"clippings", "clippings <My Clippings.txt|metadata.lua|dir...>", runClippings, true},
+		{"migrate", "migrate [dir]", runMigrate, false},
+	}
+}
+
+// Run 执行子命令，返回退出码
+func Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		if cmd.needDB {
+			// 打开数据库会在数据目录创建空的data.db，之后旧目录的数据就无法再迁移
+			if legacy, ok := config.LegacyDir(); ok {
+				fmt.Fprintf(stderr, "go-reader: found a library in %s, run \"go-reader migrate\" to move it to %s first\n", legacy, config.DataDir())
+				return ExitError
+			}
+			if err := dao.Open(config.DBPath()); err != nil {
+				fmt.Fprintln(stderr, "go-reader: failed to open database: "+err.Error())
+				return ExitError
+			}
+		}
+		err := cmd.run(args[1:])
+		switch {
+		case err == nil:
+			return ExitOK
+		case errors.Is(err, errUsage):
+			fmt.Fprintln(stderr, "usage: go-reader "+cmd.usage)
+			return ExitUsage
+		default:
+			fmt.Fprintln(stderr, "go-reader "+cmd.name+": "+err.Error())
+			return ExitError
+		}
+	}
+	fmt.Fprintln(stderr, "go-reader: unknown command "+args[0])
+	printUsage(stderr)
+	return ExitUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: go-reader [command]")
+	fmt.Fprintln(w, "without a command the reader UI is started")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintln(w, "  "+cmd.usage)
+	}
+}
+
+// parseArgs 解析参数，允许flag出现在位置参数之后
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// titleArg 取唯一的书名参数
+func titleArg(fs *flag.FlagSet, args []string) (string, error) {
+	positional, err := parseArgs(fs, args)
+	if err != nil || len(positional) != 1 {
+		return "", errUsage
+	}
+	return positional[0], nil
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	encoding := fs.String("encoding", "", "")
+	convert := fs.String("convert", "", "")
+	files, err := parseArgs(fs, args)
+	if err != nil || len(files) == 0 || *convert != "" && !views.IsConvert(*convert) {
+		return errUsage
+	}
+	failed := 0
+	for _, file := range files {
+		book, err := views.ImportBookContext(context.Background(), file, *encoding, *convert, nil)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", file, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(stdout, "imported %s as %s\n", file, book.Title)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(files))
+	}
+	return nil
+}
+
+type bookInfo struct {
+	Title       string   `json:"title"`
+	Lines       int      `json:"lines"`
+	Position    int      `json:"position"`
+	Progress    int      `json:"progress"`
+	Chapters    int      `json:"chapters,omitempty"`
+	Rule        string   `json:"chapterRule,omitempty"`
+	Encoding    string   `json:"encoding,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Series      string   `json:"series,omitempty"`
+	SeriesIndex float64  `json:"seriesIndex,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	SourcePath  string   `json:"sourcePath,omitempty"`
+	Added       string   `json:"added"`
+	Updated     string   `json:"updated"`
+}
+
+func newBookInfo(book dao.Book) bookInfo {
+	return bookInfo{
+		Title:       book.Title,
+		Lines:       book.Length,
+		Position:    book.LastPos,
+		Progress:    views.BookProgress(book),
+		Rule:        book.ChapterRule,
+		Encoding:    book.Encoding,
+		Author:      book.Author,
+		Series:      book.Series,
+		SeriesIndex: book.SeriesIndex,
+		Language:    book.Language,
+		Publisher:   book.Publisher,
+		Description: book.Description,
+		Tags:        book.TagList(),
+		SourcePath:  book.SourcePath,
+		Added:       book.CreatedAt.Format("2006-01-02 15:04:05"),
+		Updated:     book.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "")
+	positional, err := parseArgs(fs, args)
+	if err != nil || len(positional) != 0 {
+		return errUsage
+	}
+	books := dao.GetBooks()
+	if *asJSON {
+		infos := make([]bookInfo, 0, len(books))
+		for _, book := range books {
+			infos = append(infos, newBookInfo(book))
+		}
+		return printJSON(infos)
+	}
+	for _, book := range books {
+		fmt.Fprintf(stdout, "%3d%%  %s\n", views.BookProgress(book), book.Title)
+	}
+	return nil
+}
+
+func runRemove(args []string) error {
+	title, err := titleArg(flag.NewFlagSet("remove", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if _, err := dao.GetBookByName(title); err != nil {
+		return errors.New("no such book: " + title)
+	}
+	if err := views.DelBook(title); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "removed "+title)
+	return nil
+}
+
+// openBook 打开书并识别目录
+func openBook(title string) (dao.Book, error) {
+	book, err := dao.GetBookByName(title)
+	if err != nil {
+		return book, errors.New("no such book: " + title)
+	}
+	if _, err := views.ProcBook(title); err != nil {
+		return book, err
+	}
+	return book, nil
+}
+
+func runInfo(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "")
+	title, err := titleArg(fs, args)
+	if err != nil {
+		return err
+	}
+	book, err := openBook(title)
+	if err != nil {
+		return err
+	}
+	info := newBookInfo(book)
+	info.Chapters = len(views.GetBookDirs())
+	if *asJSON {
+		return printJSON(info)
+	}
+	rule := info.Rule
+	if rule == "" {
+		rule = "auto"
+	}
+	fmt.Fprintf(stdout, "title:    %s\n", info.Title)
+	printField := func(name string, value string) {
+		if value != "" {
+			fmt.Fprintf(stdout, "%-9s %s\n", name+":", value)
+		}
+	}
+	printField("author", info.Author)
+	printField("series", views.FormatSeries(book))
+	printField("language", info.Language)
+	printField("publisher", info.Publisher)
+	printField("tags", strings.Join(info.Tags, ", "))
+	fmt.Fprintf(stdout, "lines:    %d\n", info.Lines)
+	fmt.Fprintf(stdout, "chapters: %d (%s)\n", info.Chapters, rule)
+	fmt.Fprintf(stdout, "progress: %d%% (line %d)\n", info.Progress, info.Position)
+	if info.Encoding != "" {
+		fmt.Fprintf(stdout, "encoding: %s\n", info.Encoding)
+	}
+	fmt.Fprintf(stdout, "added:    %s\n", info.Added)
+	fmt.Fprintf(stdout, "updated:  %s\n", info.Updated)
+	printField("source", info.SourcePath)
+	printField("about", info.Description)
+	return nil
+}
+
+func runToc(args []string) error {
+	title, err := titleArg(flag.NewFlagSet("toc", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if _, err := openBook(title); err != nil {
+		return err
+	}
+	for i, dir := range views.GetBookDirs() {
+		indent := ""
+		if dir.Parent() >= 0 {
+			indent = "  "
+		}
+		fmt.Fprintf(stdout, "%5d  %s%s\n", i+1, indent, dir.Name())
+	}
+	return nil
+}
+
+func runCat(args []string) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	chapter := fs.Int("chapter", -1, "")
+	title, err := titleArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if _, err := openBook(title); err != nil {
+		return err
+	}
+	dirs := views.GetBookDirs()
+	if *chapter < 0 {
+		// 未指定章节时输出全书
+		_, content, _ := views.GetBookContent(-1)
+		lines := []string{content}
+		for i := range dirs {
+			name, chapterContent, _ := views.GetBookContent(views.GetChapterStart(i))
+			lines = append(lines, name)
+			if chapterContent != "" {
+				lines = append(lines, chapterContent)
+			}
+		}
+		if content == "" {
+			lines = lines[1:]
+		}
+		fmt.Fprintln(stdout, strings.Join(lines, "\n"))
+		return nil
+	}
+	// 第0章为第一章之前的内容
+	if *chapter > len(dirs) {
+		return fmt.Errorf("chapter %d out of range 0-%d", *chapter, len(dirs))
+	}
+	name, content, _ := views.GetBookContent(views.GetChapterStart(*chapter - 1))
+	if name != "" {
+		fmt.Fprintln(stdout, name)
+	}
+	fmt.Fprintln(stdout, content)
+	return nil
+}
+
+func runProgress(args []string) error {
+	fs := flag.NewFlagSet("progress", flag.ContinueOnError)
+	set := fs.String("set", "", "")
+	title, err := titleArg(fs, args)
+	if err != nil {
+		return err
+	}
+	book, err := dao.GetBookByName(title)
+	if err != nil {
+		return errors.New("no such book: " + title)
+	}
+	if *set != "" {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(*set, "%"), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return errors.New("invalid progress " + *set + ", expected 0-100%")
+		}
+		book.LastPos = int(percent * float64(max(book.Length-1, 0)) / 100)
+		if err := dao.UpdateBookPos(title, book.LastPos); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "%d%% (line %d of %d)\n", views.BookProgress(book), book.LastPos, book.Length)
+	return nil
+}
+
+// runExport 导出为EPUB或Markdown，未指定格式时按输出文件的扩展名判断
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "")
+	output := fs.String("o", "", "")
+	title, err := titleArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*output)) {
+		case ".md", ".markdown":
+			*format = views.ExportMarkdown
+		default:
+			*format = views.ExportEPUB
+		}
+	}
+	if *format == "markdown" {
+		*format = views.ExportMarkdown
+	}
+	path, err := views.ExportBook(title, *format, *output)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "exported %s to %s\n", title, path)
+	return nil
+}
+
+// runClippings 导入Kindle、KOReader的标注，列出无法对应的条目
+func runClippings(args []string) error {
+	paths, err := parseArgs(flag.NewFlagSet("clippings", flag.ContinueOnError), args)
+	if err != nil || len(paths) == 0 {
+		return errUsage
+	}
+	report, err := views.ImportClippings(paths)
+	if err != nil {
+		return err
+	}
+	for _, u := range report.Unmatched {
+		fmt.Fprintf(stderr, "unmatched: %s: %s: %s\n", u.Title, u.Reason, u.Snippet())
+	}
+	fmt.Fprintln(stdout, report.Summary())
+	return nil
+}
+
+// runMigrate 将旧版本放在dir(默认当前目录)下的数据移动到数据目录
+func runMigrate(args []string) error {
+	positional, err := parseArgs(flag.NewFlagSet("migrate", flag.ContinueOnError), args)
+	if err != nil || len(positional) > 1 {
+		return errUsage
+	}
+	dir, ok := config.LegacyDir()
+	if len(positional) == 1 {
+		dir, ok = positional[0], true
+	}
+	if !ok {
+		return errors.New("nothing to migrate")
+	}
+	if err := config.Migrate(dir); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "moved library from "+dir+" to "+config.DataDir())
+	return nil
+}
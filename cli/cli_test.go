@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-reader/config"
+	"go-reader/dao"
+)
+
+// 旧版本把data.db放在当前目录，迁移前运行其他子命令不能在数据目录创建数据库
+func TestCommandBeforeMigrate(t *testing.T) {
+	legacy, dataDir := t.TempDir(), t.TempDir()
+	if err := dao.Open(filepath.Join(legacy, "data.db")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dao.CreateBook("旧书", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Init(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(legacy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	var out, errOut bytes.Buffer
+	stdout, stderr = &out, &errOut
+	t.Cleanup(func() { stdout, stderr = os.Stdout, os.Stderr })
+
+	if code := Run([]string{"list"}); code != ExitError {
+		t.Fatalf("list before migrate = %d, want %d", code, ExitError)
+	}
+	if !strings.Contains(errOut.String(), "go-reader migrate") {
+		t.Errorf("stderr = %q, want a hint to migrate", errOut.String())
+	}
+	if _, err := os.Stat(config.DBPath()); !os.IsNotExist(err) {
+		t.Fatalf("list created %s before migrating", config.DBPath())
+	}
+
+	if code := Run([]string{"migrate"}); code != ExitOK {
+		t.Fatalf("migrate = %d: %s", code, errOut.String())
+	}
+	out.Reset()
+	if code := Run([]string{"list"}); code != ExitOK {
+		t.Fatalf("list after migrate = %d: %s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "旧书") {
+		t.Errorf("list after migrate = %q, want the migrated book", out.String())
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	appName     = "go-reader"
+	DataDirEnv  = "GO_READER_DATA_DIR" // 覆盖数据目录的环境变量
+	dbFileName  = "data.db"
+	bookDirName = "download"
+	exportDir   = "export"
+)
+
+var dataDir string
+
+// Init 确定数据目录并创建，dir为空时依次使用环境变量GO_READER_DATA_DIR、$XDG_DATA_HOME/go-reader
+func Init(dir string) (err error) {
+	if dir == "" {
+		dir = os.Getenv(DataDirEnv)
+	}
+	if dir == "" {
+		dir, err = defaultDataDir()
+		if err != nil {
+			return
+		}
+	}
+	dataDir, err = filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	return os.MkdirAll(BookDir(), 0755)
+}
+
+func defaultDataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, appName), nil
+	}
+	if runtime.GOOS == "windows" {
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			return filepath.Join(local, appName), nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appName), nil
+}
+
+func DataDir() string {
+	return dataDir
+}
+
+func DBPath() string {
+	return filepath.Join(dataDir, dbFileName)
+}
+
+// BookDir 导入后的书所在目录
+func BookDir() string {
+	return filepath.Join(dataDir, bookDirName)
+}
+
+// BookPath 导入后的书的路径
+func BookPath(title string) string {
+	return filepath.Join(BookDir(), title+".txt")
+}
+
+// ExportDir 书架中导出的书所在目录
+func ExportDir() string {
+	return filepath.Join(dataDir, exportDir)
+}
+
+// LegacyDir 旧版本把数据放在当前目录，数据目录里还没有数据库时返回当前目录
+func LegacyDir() (string, bool) {
+	cwd, err := os.Getwd()
+	if err != nil || cwd == dataDir {
+		return "", false
+	}
+	if _, err := os.Stat(filepath.Join(cwd, dbFileName)); err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(DBPath()); err == nil {
+		return "", false
+	}
+	return cwd, true
+}
+
+// Migrate 将旧目录下的data.db和download移动到数据目录
+func Migrate(legacyDir string) error {
+	if _, err := os.Stat(DBPath()); err == nil {
+		return errors.New("database already exists in " + dataDir)
+	}
+	if err := move(filepath.Join(legacyDir, dbFileName), DBPath()); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(filepath.Join(legacyDir, bookDirName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := move(filepath.Join(legacyDir, bookDirName, entry.Name()), filepath.Join(BookDir(), entry.Name())); err != nil {
+			return err
+		}
+	}
+	// 目录为空时删除
+	os.Remove(filepath.Join(legacyDir, bookDirName))
+	return nil
+}
+
+// move 重命名文件，跨分区时复制后删除
+func move(src string, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -12,30 +13,77 @@ type Book struct {
 	ID        uint `gorm:"primarykey"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Title     string `gorm:"unique;not null"`
-	Length    int    `gorm:"not null"`
-	LastPos   int    `gorm:"not null;default:0"`
+	Title     string    `gorm:"unique;not null"`
+	Length    int       `gorm:"not null"`
+	LastPos   int       `gorm:"not null;default:0"`
+	ReadAt    time.Time // 最后阅读的时间
+	// 章节识别规则，为空时自动识别，custom时使用ChapterRegex
+	ChapterRule  string
+	ChapterRegex string
+	// 导入时使用的编码，epub为空
+	Encoding string
+	// 阅读时的简繁转换: s2t、t2s，为空时不转换
+	Convert string
+	// 元数据
+	Author      string
+	Series      string
+	SeriesIndex float64
+	Language    string
+	Publisher   string
+	Description string
+	Tags        string // 以逗号分隔
+	SourcePath  string // 导入时原文件的路径
 }
 
+// _metadataColumns UpdateBookMetadata更新的字段
+var _metadataColumns = []string{"author", "series", "series_index", "language", "publisher", "description", "tags"}
+
 var db *gorm.DB
 
-func init() {
-	var err error
-	db, err = gorm.Open(sqlite.Open("data.db"), &gorm.Config{
+// Open 打开数据库，使用其他函数前必须先调用
+func Open(path string) (err error) {
+	db, err = gorm.Open(sqlite.Open(path), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
-		panic("failed to connect database")
+		return
 	}
-	db.AutoMigrate(&Book{})
+	return db.AutoMigrate(&Book{}, &Chapter{}, &Bookmark{}, &Setting{}, &Session{}, &Highlight{})
 }
 
 func CreateBook(title string, length int) (book Book, err error) {
-	err = db.Create(&Book{Title: title, Length: length}).Error
+	book = Book{Title: title, Length: length}
+	err = db.Create(&book).Error
 	return
 }
 func UpdateBookPos(title string, pos int) error {
-	return db.Model(&Book{}).Where("title = ?", title).Update("last_pos", pos).Error
+	return db.Model(&Book{}).Where("title = ?", title).
+		Updates(map[string]interface{}{"last_pos": pos, "read_at": time.Now()}).Error
+}
+
+func UpdateBookChapterRule(title string, rule string, regex string) error {
+	return db.Model(&Book{}).Where("title = ?", title).
+		Updates(map[string]interface{}{"chapter_rule": rule, "chapter_regex": regex}).Error
+}
+
+func UpdateBookConvert(title string, convert string) error {
+	return db.Model(&Book{}).Where("title = ?", title).Update("convert", convert).Error
+}
+
+// UpdateBookMetadata 更新书的元数据，空值也会写入
+func UpdateBookMetadata(book Book) error {
+	return db.Model(&Book{ID: book.ID}).Select(_metadataColumns).Updates(&book).Error
+}
+
+// TagList 拆分Tags
+func (b Book) TagList() []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.FieldsFunc(b.Tags, func(r rune) bool { return r == ',' || r == '，' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
 
 func GetBooks() (books []Book) {
@@ -51,10 +99,22 @@ func GetBookByName(name string) (book Book, err error) {
 	return
 }
 
+// DeleteBook 在同一事务中删除书和书的目录、书签、阅读记录、标注
 func DeleteBook(id uint) error {
-	return db.Delete(&Book{}, id).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&Chapter{}, &Bookmark{}, &Session{}, &Highlight{}} {
+			if err := tx.Where("book_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&Book{}, id).Error
+	})
 }
 
 func DeleteBookByName(name string) error {
-	return db.Where("title = ?", name).Delete(&Book{}).Error
+	book, err := GetBookByName(name)
+	if err != nil {
+		return err
+	}
+	return DeleteBook(book.ID)
 }
@@ -0,0 +1,33 @@
+package dao
+
+import "time"
+
+type Bookmark struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	BookID    uint   `gorm:"index;not null"`
+	Pos       int    `gorm:"not null"`
+	Chapter   string `gorm:"not null"`
+	Snippet   string `gorm:"not null"`
+	Label     string
+}
+
+func CreateBookmark(bookmark Bookmark) (Bookmark, error) {
+	err := db.Create(&bookmark).Error
+	return bookmark, err
+}
+
+func GetBookmarks(bookID uint) (bookmarks []Bookmark) {
+	if err := db.Where("book_id = ?", bookID).Order("pos").Find(&bookmarks).Error; err != nil {
+		return []Bookmark{}
+	}
+	return bookmarks
+}
+
+func UpdateBookmarkLabel(id uint, label string) error {
+	return db.Model(&Bookmark{}).Where("id = ?", id).Update("label", label).Error
+}
+
+func DeleteBookmark(id uint) error {
+	return db.Delete(&Bookmark{}, id).Error
+}
@@ -0,0 +1,36 @@
+package dao
+
+import "gorm.io/gorm"
+
+// Chapter 导入时解析出的目录(如epub的toc)，Start为章节名所在行
+type Chapter struct {
+	ID     uint   `gorm:"primarykey"`
+	BookID uint   `gorm:"index;not null"`
+	Title  string `gorm:"not null"`
+	Start  int    `gorm:"not null"`
+	Volume bool   `gorm:"not null;default:false"`
+}
+
+// CreateBookWithChapters 在同一事务中创建书和目录
+func CreateBookWithChapters(book Book, chapters []Chapter) (Book, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&book).Error; err != nil {
+			return err
+		}
+		if len(chapters) == 0 {
+			return nil
+		}
+		for i := range chapters {
+			chapters[i].BookID = book.ID
+		}
+		return tx.Create(&chapters).Error
+	})
+	return book, err
+}
+
+func GetChapters(bookID uint) (chapters []Chapter) {
+	if err := db.Where("book_id = ?", bookID).Order("start").Find(&chapters).Error; err != nil {
+		return []Chapter{}
+	}
+	return chapters
+}
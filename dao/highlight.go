@@ -0,0 +1,38 @@
+package dao
+
+import "time"
+
+// Highlight 标注的一段文字，位置为在书中的行号和行内字节偏移，End不包含
+type Highlight struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	BookID    uint   `gorm:"index;not null"`
+	StartLine int    `gorm:"not null"`
+	StartCol  int    `gorm:"not null"`
+	EndLine   int    `gorm:"not null"`
+	EndCol    int    `gorm:"not null"`
+	Text      string `gorm:"not null"`
+	Note      string
+	Source    string // 从其他阅读器导入时的来源，为空时是在本程序中添加的
+}
+
+func CreateHighlight(highlight Highlight) (Highlight, error) {
+	err := db.Create(&highlight).Error
+	return highlight, err
+}
+
+// GetHighlights 按位置排序
+func GetHighlights(bookID uint) (highlights []Highlight) {
+	if err := db.Where("book_id = ?", bookID).Order("start_line, start_col").Find(&highlights).Error; err != nil {
+		return []Highlight{}
+	}
+	return highlights
+}
+
+func UpdateHighlightNote(id uint, note string) error {
+	return db.Model(&Highlight{}).Where("id = ?", id).Update("note", note).Error
+}
+
+func DeleteHighlight(id uint) error {
+	return db.Delete(&Highlight{}, id).Error
+}
@@ -0,0 +1,33 @@
+package dao
+
+import "time"
+
+// Session 一次阅读，Lines和Pages为向后翻过的行数和页数
+type Session struct {
+	ID       uint      `gorm:"primarykey"`
+	BookID   uint      `gorm:"index;not null"`
+	StartAt  time.Time `gorm:"index;not null"`
+	EndAt    time.Time `gorm:"not null"`
+	StartPos int       `gorm:"not null"`
+	EndPos   int       `gorm:"not null"`
+	Lines    int       `gorm:"not null;default:0"`
+	Pages    int       `gorm:"not null;default:0"`
+}
+
+// Duration 阅读时长
+func (s Session) Duration() time.Duration {
+	return s.EndAt.Sub(s.StartAt)
+}
+
+// SaveSession 新建或更新阅读记录
+func SaveSession(session *Session) error {
+	return db.Save(session).Error
+}
+
+// GetSessions 返回start之后开始的所有阅读记录，按时间排序
+func GetSessions(start time.Time) (sessions []Session) {
+	if err := db.Where("start_at >= ?", start).Order("start_at").Find(&sessions).Error; err != nil {
+		return []Session{}
+	}
+	return sessions
+}
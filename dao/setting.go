@@ -0,0 +1,22 @@
+package dao
+
+import "gorm.io/gorm/clause"
+
+// Setting 界面设置，重启后保留
+type Setting struct {
+	Key   string `gorm:"primarykey"`
+	Value string `gorm:"not null"`
+}
+
+// GetSetting 读取设置，不存在时返回def
+func GetSetting(key string, def string) string {
+	var setting Setting
+	if err := db.Where("key = ?", key).First(&setting).Error; err != nil {
+		return def
+	}
+	return setting.Value
+}
+
+func SetSetting(key string, value string) error {
+	return db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&Setting{Key: key, Value: value}).Error
+}
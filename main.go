@@ -3,10 +3,58 @@ package main
 // This example demonstrates various Lip Gloss style and layout features.
 
 import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
 	_ "go-reader/env" // 设置env，必须第一个导入
+
+	"go-reader/cli"
+	"go-reader/config"
+	"go-reader/dao"
 	"go-reader/views"
 )
 
 func main() {
+	dataDir := flag.String("data-dir", "", "data directory (default $"+config.DataDirEnv+" or $XDG_DATA_HOME/go-reader)")
+	flag.Usage = func() {
+		cli.Run([]string{"help"})
+		fmt.Fprintln(flag.CommandLine.Output(), "\noptions:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := config.Init(*dataDir); err != nil {
+		fmt.Fprintln(os.Stderr, "go-reader: "+err.Error())
+		os.Exit(cli.ExitError)
+	}
+	args := flag.Args()
+	// 带子命令时不启动界面，供脚本调用，未迁移时由子命令报错
+	if len(args) > 0 {
+		os.Exit(cli.Run(args))
+	}
+	if legacy, ok := config.LegacyDir(); ok {
+		offerMigration(legacy)
+	}
+	if err := dao.Open(config.DBPath()); err != nil {
+		fmt.Fprintln(os.Stderr, "go-reader: failed to open database: "+err.Error())
+		os.Exit(cli.ExitError)
+	}
 	views.NewViews()
 }
+
+// offerMigration 启动界面前询问是否迁移当前目录下的旧数据
+func offerMigration(legacy string) {
+	fmt.Printf("Found data.db and download/ in %s.\nMove them to %s? [Y/n] ", legacy, config.DataDir())
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "" && answer != "y" && answer != "yes" {
+		return
+	}
+	if err := config.Migrate(legacy); err != nil {
+		fmt.Fprintln(os.Stderr, "go-reader: migration failed: "+err.Error())
+		os.Exit(cli.ExitError)
+	}
+}
@@ -8,6 +8,7 @@ import (
 type Debouncer struct {
 	duration time.Duration
 	timer    *time.Timer
+	f        func()
 	mu       sync.Mutex
 }
 
@@ -23,5 +24,16 @@ func (d *Debouncer) Debounce(f func()) {
 	if d.timer != nil {
 		d.timer.Stop()
 	}
+	d.f = f
 	d.timer = time.AfterFunc(d.duration, f)
 }
+
+// Flush 立即执行还未执行的f，退出前调用，没有等待中的f时什么也不做
+func (d *Debouncer) Flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil && d.timer.Stop() {
+		d.f()
+	}
+	d.timer = nil
+}
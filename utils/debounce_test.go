@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebouncerFlush(t *testing.T) {
+	d := NewDebouncer(60 * 1000)
+	calls := make([]int, 0)
+	d.Debounce(func() { calls = append(calls, 1) })
+	d.Debounce(func() { calls = append(calls, 2) })
+	d.Flush()
+	if len(calls) != 1 || calls[0] != 2 {
+		t.Fatalf("calls after Flush = %v, want [2]", calls)
+	}
+	// 已经执行过的不再执行
+	d.Flush()
+	if len(calls) != 1 {
+		t.Errorf("second Flush ran f again: %v", calls)
+	}
+}
+
+func TestDebouncerFlushAfterFired(t *testing.T) {
+	d := NewDebouncer(1)
+	done := make(chan struct{}, 2)
+	d.Debounce(func() { done <- struct{}{} })
+	<-done
+	d.Flush()
+	select {
+	case <-done:
+		t.Error("Flush ran f that had already fired")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
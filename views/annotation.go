@@ -0,0 +1,197 @@
+package views
+
+import (
+	"strings"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+type annotationMsg struct{}
+
+func annotationCmd() tea.Cmd {
+	return func() tea.Msg {
+		return annotationMsg{}
+	}
+}
+
+type keyMapAnnotation struct {
+	Back   key.Binding
+	Select key.Binding
+	Edit   key.Binding
+	Remove key.Binding
+}
+
+var _keysAnnotation = keyMapAnnotation{
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q", "A"),
+		key.WithHelp("q", "back"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "jump"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit note"),
+	),
+	Remove: key.NewBinding(
+		key.WithKeys("r", "delete"),
+		key.WithHelp("r", "remove"),
+	),
+}
+
+type itemAnnotation struct {
+	dao.Highlight
+	chapter string
+}
+
+// Title 有笔记时显示笔记，否则显示摘录
+func (i itemAnnotation) Title() string {
+	if i.Note != "" {
+		return strings.ReplaceAll(i.Note, "\n", " ")
+	}
+	return runewidth.Truncate(strings.ReplaceAll(i.Text, "\n", " "), _snippetWidth, "…")
+}
+func (i itemAnnotation) Description() string {
+	desc := i.chapter
+	if i.Source != "" {
+		desc = "[" + i.Source + "] " + desc
+	}
+	if i.Note != "" {
+		desc += "  " + strings.ReplaceAll(i.Text, "\n", " ")
+	}
+	return desc
+}
+func (i itemAnnotation) FilterValue() string { return i.Note + i.chapter + i.Text }
+
+type modelAnnotation struct {
+	list  list.Model
+	input textinput.Model
+}
+
+func (m modelAnnotation) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelAnnotation) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := _docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v-2)
+		return m, nil
+	case tea.KeyMsg:
+		if m.input.Focused() {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+				m.input.Blur()
+				_typing = false
+				return m, nil
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+				m.input.Blur()
+				_typing = false
+				item, ok := m.list.SelectedItem().(itemAnnotation)
+				if !ok {
+					return m, nil
+				}
+				if err := dao.UpdateHighlightNote(item.ID, strings.TrimSpace(m.input.Value())); err != nil {
+					return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Save Failed", Confirm: "OK"})
+				}
+				loadHighlights()
+				return m, annotationCmd()
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if key.Matches(msg, _keysAnnotation.Back) {
+			return m, tea.Batch(pagerRefreshCmd(), viewCmd(viewPager))
+		}
+		item, ok := m.list.SelectedItem().(itemAnnotation)
+		if !ok {
+			break
+		}
+		switch {
+		case key.Matches(msg, _keysAnnotation.Select):
+			title, content, index := GetBookContent(item.StartLine)
+			return m, tea.Batch(
+				pagerCmd(pagerMsg{title: title, content: content, lastPos: item.StartLine, col: item.StartCol, currentIndex: index}),
+				viewCmd(viewPager),
+			)
+		case key.Matches(msg, _keysAnnotation.Edit):
+			m.input.SetValue(item.Note)
+			m.input.CursorEnd()
+			_typing = true
+			return m, m.input.Focus()
+		case key.Matches(msg, _keysAnnotation.Remove):
+			return m, dialogCmd(dialogMsg{
+				Type:    DialogDefault,
+				Title:   "Delete highlight?",
+				Confirm: "Delete",
+				Cancel:  "Cancel",
+				ConfirmFunc: func() tea.Cmd {
+					if err := dao.DeleteHighlight(item.ID); err != nil {
+						return dialogCmd(dialogMsg{Type: DialogAlert, Title: "Delete Failed", Confirm: "OK"})
+					}
+					loadHighlights()
+					return tea.Batch(dialogCmd(dialogMsg{Type: DialogNone}), annotationCmd())
+				},
+			})
+		}
+	case annotationMsg:
+		items := []list.Item{}
+		for _, highlight := range bookHighlights {
+			chapter := GetChapterPath(GetChapterIndex(highlight.StartLine))
+			if chapter == "" {
+				chapter = bookName
+			}
+			items = append(items, itemAnnotation{Highlight: highlight, chapter: chapter})
+		}
+		index := m.list.Index()
+		cmd = m.list.SetItems(items)
+		if index >= len(items) {
+			index = len(items) - 1
+		}
+		m.list.Select(index)
+		return m, cmd
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		_typing = m.list.FilterState() == list.Filtering
+	}
+	return m, cmd
+}
+
+func (m modelAnnotation) View() string {
+	if m.input.Focused() {
+		return _docStyle.Render(m.list.View() + "\n" + m.input.View())
+	}
+	return _docStyle.Render(m.list.View() + "\n")
+}
+
+func NewAnnotation() modelAnnotation {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Annotations"
+	l.Styles.Title = titleStyle
+	l.SetStatusBarItemName("highlight", "highlights")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysAnnotation.Select, _keysAnnotation.Edit, _keysAnnotation.Remove}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysAnnotation.Select, _keysAnnotation.Edit, _keysAnnotation.Remove}
+	}
+
+	input := textinput.New()
+	input.Prompt = "note: "
+
+	return modelAnnotation{list: l, input: input}
+}
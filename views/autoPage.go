@@ -0,0 +1,108 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// 自动翻页的间隔，保存在设置中
+const (
+	_settingAutoInterval = "pager.autoInterval"
+	_autoIntervalDefault = 30 * time.Second
+	_autoIntervalMin     = 2 * time.Second
+	_autoIntervalMax     = 10 * time.Minute
+)
+
+var _autoStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("230")).Background(lipgloss.Color("62")).Padding(0, 1)
+
+var _keysAutoPage = struct {
+	Toggle key.Binding
+	Faster key.Binding
+	Slower key.Binding
+	Pause  key.Binding
+}{
+	Toggle: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "auto page")),
+	Faster: key.NewBinding(key.WithKeys("-", "_"), key.WithHelp("-/+", "interval")),
+	Slower: key.NewBinding(key.WithKeys("+", "=")),
+	Pause:  key.NewBinding(key.WithKeys("any"), key.WithHelp("any key", "pause")),
+}
+
+// autoTickMsg 自动翻页每秒一次的计时，id不是当前的计时时忽略
+type autoTickMsg struct {
+	id int
+}
+
+func autoTickCmd(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return autoTickMsg{id: id}
+	})
+}
+
+// autoPage 自动翻页的状态
+type autoPage struct {
+	on       bool
+	id       int
+	interval time.Duration
+	left     time.Duration // 距下次翻页的时间
+}
+
+func loadAutoInterval() time.Duration {
+	seconds, err := strconv.Atoi(dao.GetSetting(_settingAutoInterval, ""))
+	if err != nil {
+		return _autoIntervalDefault
+	}
+	return min(max(time.Duration(seconds)*time.Second, _autoIntervalMin), _autoIntervalMax)
+}
+
+// start 开始计时，之前的计时作废
+func (a *autoPage) start() tea.Cmd {
+	if a.interval == 0 {
+		a.interval = loadAutoInterval()
+	}
+	a.on = true
+	a.id++
+	a.left = a.interval
+	return autoTickCmd(a.id)
+}
+
+func (a *autoPage) stop() {
+	a.on = false
+	a.id++
+}
+
+// adjust 调整间隔，10秒以内每次1秒，以上每次5秒
+func (a *autoPage) adjust(longer bool) {
+	step := 5 * time.Second
+	if a.interval < 10*time.Second || (!longer && a.interval == 10*time.Second) {
+		step = time.Second
+	}
+	if !longer {
+		step = -step
+	}
+	interval := min(max(a.interval+step, _autoIntervalMin), _autoIntervalMax)
+	a.left = min(max(a.left+interval-a.interval, time.Second), interval)
+	a.interval = interval
+	dao.SetSetting(_settingAutoInterval, strconv.Itoa(int(interval/time.Second)))
+}
+
+// tick 返回是否该翻页了
+func (a *autoPage) tick() bool {
+	a.left -= time.Second
+	if a.left > 0 {
+		return false
+	}
+	a.left = a.interval
+	return true
+}
+
+// indicator 显示在页脚的倒计时
+func (a autoPage) indicator() string {
+	return _autoStyle.Render(fmt.Sprintf("▶ %ds", int(a.left/time.Second))) + subTitleStyle.Render(fmt.Sprintf("every %ds", int(a.interval/time.Second)))
+}
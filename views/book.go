@@ -2,118 +2,257 @@ package views
 
 import (
 	"bufio"
+	"context"
 	"errors"
+	"go-reader/config"
 	"go-reader/dao"
 	"go-reader/utils"
+	"io"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/saintfish/chardet"
-	"golang.org/x/text/encoding/simplifiedchinese"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/transform"
 )
 
+// BookDir 目录项，卷(volume)下包含若干章节，bookDirs按行号顺序展平保存整棵树
 type BookDir struct {
-	name  string
-	start int
+	name     string
+	start    int
+	volume   bool
+	parent   int   // 所属卷在bookDirs中的下标，-1为顶层
+	children []int // 卷下章节在bookDirs中的下标
 }
 
+const _snippetWidth = 60 // 书签等摘录的最大宽度
+
+func (d BookDir) Name() string   { return d.name }
+func (d BookDir) Start() int     { return d.start }
+func (d BookDir) IsVolume() bool { return d.volume }
+func (d BookDir) Parent() int    { return d.parent }
+
 var updateBookPosDebounce = utils.NewDebouncer(200)
 
 var bookAll []string
 var bookDirs []BookDir
 var bookName string
+var bookID uint
+var bookPos int // 当前阅读位置
+var bookRule, bookRegex string
+var bookConvert string // 阅读时的简繁转换
 
 func init() {
 	bookAll = make([]string, 0)
 	bookDirs = make([]BookDir, 0)
 }
 
+// ImportProgress 导入进度，Read为已读取的原文件字节数
+type ImportProgress struct {
+	Read     int64
+	Total    int64
+	Lines    int
+	Encoding string
+}
+
+const _importCheckLines = 2000 // 每处理多少行检查一次取消并报告进度
+
 func ImportBook(filepath string) (err error) {
-	all := make([]string, 0)
-	cnt := 0
-	// 读取txt文件
-	file, err := os.Open(filepath)
-	if err != nil {
+	_, err = ImportBookContext(context.Background(), filepath, "", "", nil)
+	return
+}
+
+// ImportBookContext 导入书，charset为空时自动识别txt的编码，返回导入后的书
+// 书名和作者等元数据依次取自epub、txt开头和文件名
+// convert不为空时导入时转换简繁，保存转换后的内容
+// ctx取消时不会留下写了一半的文件或数据库记录
+func ImportBookContext(ctx context.Context, file string, charset string, convert string, progress func(ImportProgress)) (book dao.Book, err error) {
+	if progress == nil {
+		progress = func(ImportProgress) {}
+	}
+	var all []string
+	var dirs []BookDir
+	filename, ext, _ := PathProc(file)
+	if strings.ToLower(ext) == ".epub" {
+		var epub epubBook
+		epub, err = readEpub(ctx, file, progress)
+		if err != nil {
+			return
+		}
+		all, dirs = epub.lines, epub.dirs
+		book = epub.meta
+	} else {
+		all, charset, err = readTxt(ctx, file, charset, progress)
+		if err != nil {
+			return
+		}
+		book = metaFromHeader(all)
+		book.Encoding = charset
+	}
+	book = mergeMeta(book, metaFromFileName(filename))
+	if convert != "" {
+		if err = convertBook(ctx, &book, all, dirs, convert); err != nil {
+			return
+		}
+	}
+	book.Title = safeTitle(book.Title)
+	if book.Title == "" {
+		book.Title = filename
+	}
+	bookname := book.Title
+	if _, e := dao.GetBookByName(bookname); e == nil {
+		err = errors.New(bookname + " already exists")
+		return
+	}
+	book.Length = len(all)
+	if book.SourcePath, err = filepath.Abs(file); err != nil {
 		return
 	}
-	defer file.Close()
 
-	// /** 识别文件编码 **/
-	reader := bufio.NewReader(file)
-	b, err := reader.Peek(4096) // Peek at the first 1024 bytes
+	// 先写入临时文件，写完后再写数据库并改名
+	err = os.MkdirAll(config.BookDir(), 0755)
 	if err != nil {
 		return
 	}
-	// Detect the encoding
-	result, err := chardet.NewTextDetector().DetectBest(b)
+	path := config.BookPath(bookname)
+	tmpPath := path + ".part"
+	err = writeLines(ctx, tmpPath, all)
 	if err != nil {
+		os.Remove(tmpPath)
 		return
 	}
-	useTrans := true
-	// 判断result.Charset是否以GB开头
-	if result.Charset[:2] == "GB" || result.Charset[:2] == "gb" {
-		useTrans = true
-	} else if result.Charset[:3] == "UTF" || result.Charset[:3] == "utf" {
-		useTrans = false
-	} else {
-		err = errors.New("Unknown encoding: " + result.Charset)
+
+	// 写入数据库
+	chapters := make([]dao.Chapter, 0, len(dirs))
+	for _, dir := range dirs {
+		chapters = append(chapters, dao.Chapter{Title: dir.name, Start: dir.start, Volume: dir.volume})
+	}
+	book, err = dao.CreateBookWithChapters(book, chapters)
+	if err != nil {
+		os.Remove(tmpPath)
 		return
 	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		dao.DeleteBook(book.ID)
+	}
+	return
+}
+
+// convertBook 转换书的内容、目录和元数据
+func convertBook(ctx context.Context, book *dao.Book, lines []string, dirs []BookDir, convert string) error {
+	for i := range lines {
+		if i%_importCheckLines == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lines[i] = Convert(lines[i], convert)
+	}
+	for i := range dirs {
+		dirs[i].name = Convert(dirs[i].name, convert)
+	}
+	for _, field := range []*string{&book.Title, &book.Author, &book.Series, &book.Publisher, &book.Description, &book.Tags} {
+		*field = Convert(*field, convert)
+	}
+	return nil
+}
 
-	_, err = file.Seek(0, 0)
+func writeLines(ctx context.Context, path string, lines []string) (err error) {
+	newFile, err := os.Create(path)
 	if err != nil {
 		return
 	}
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var line string
-		if useTrans {
-			src := scanner.Text()
-			buffer := make([]byte, len([]byte(src))*2)
-			n, _, _ := simplifiedchinese.GB18030.NewDecoder().Transform(buffer, []byte(src), true)
-			line = string(buffer[:n])
-		} else {
-			line = scanner.Text()
+	defer newFile.Close()
+	writer := bufio.NewWriter(newFile)
+	for i, line := range lines {
+		if i%_importCheckLines == 0 && ctx.Err() != nil {
+			return ctx.Err()
 		}
-		// 删除所有空行
-		if line != "" {
-			all = append(all, line)
-			cnt++
+		if _, err = writer.WriteString(line + "\n"); err != nil {
+			return
 		}
 	}
-	err = scanner.Err()
+	return writer.Flush()
+}
+
+// countingReader 统计已读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func readTxt(ctx context.Context, filepath string, charset string, progress func(ImportProgress)) (all []string, used string, err error) {
+	all = make([]string, 0)
+	// 读取txt文件
+	file, err := os.Open(filepath)
 	if err != nil {
 		return
 	}
-
-	// 写入数据库
-	bookname, ext, _ := PathProc(filepath)
-	_, err = dao.CreateBook(bookname, cnt)
+	defer file.Close()
+	stat, err := file.Stat()
 	if err != nil {
 		return
 	}
 
-	// 在当前目录下创建新的txt文件
-	// 检查目录是否存在，如果不存在则创建
-	_, err = os.Stat("download")
-	if os.IsNotExist(err) {
-		err = os.Mkdir("download", 0755)
+	// /** 识别文件编码 **/
+	reader := bufio.NewReaderSize(file, _detectSize)
+	b, err := reader.Peek(_detectSize)
+	if err != nil && !(err == io.EOF && len(b) > 0) {
+		return
+	}
+	if charset == "" {
+		charset, err = detectEncoding(b, err == nil)
 		if err != nil {
 			return
 		}
 	}
-	newFile, err := os.Create("download/" + bookname + ext)
-	if err != nil {
+	name, enc, ok := lookupCharset(charset)
+	if !ok {
+		err = errors.New("Unknown encoding: " + charset)
 		return
 	}
-	defer newFile.Close()
-	writer := bufio.NewWriter(newFile)
-	for _, line := range all {
-		writer.WriteString(line + "\n")
+	charset, used = name, name
+	// 跳过与编码一致的BOM
+	bomCharset, bomLen := detectBOM(b)
+	if bomCharset != charset {
+		bomLen = 0
+	}
+	if _, err = reader.Discard(bomLen); err != nil {
+		return
 	}
-	writer.Flush()
+
+	counter := &countingReader{r: reader, n: int64(bomLen)}
+	var src io.Reader = counter
+	if charset != "UTF-8" {
+		src = transform.NewReader(counter, enc.NewDecoder())
+	}
+	report := func() {
+		progress(ImportProgress{Read: counter.n, Total: stat.Size(), Lines: len(all), Encoding: charset})
+	}
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for i := 0; scanner.Scan(); i++ {
+		if i%_importCheckLines == 0 {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			report()
+		}
+		line := scanner.Text()
+		// 删除所有空行
+		if line != "" {
+			all = append(all, line)
+		}
+	}
+	err = scanner.Err()
+	report()
 	return
 }
 
@@ -123,68 +262,145 @@ func ProcBook(filename string) (bookLastPos int, err error) {
 		return
 	}
 	bookName = book.Title
+	bookID = book.ID
 	bookLastPos = book.LastPos
-	path := "download/" + filename + ".txt"
-	bookAll = make([]string, 0)
-	bookDirs = make([]BookDir, 0)
-	cnt := 0
+	bookPos = book.LastPos
+	bookRule, bookRegex = book.ChapterRule, book.ChapterRegex
+	bookConvert = book.Convert
+	bookSpeed = readingSpeed(book.ID)
+	ClearSearch()
+	loadHighlights()
+	bookAll, bookDirs, err = loadBook(book)
+	return
+}
+
+// loadBook 读取书的内容并识别章节，不改变当前打开的书
+func loadBook(book dao.Book) (lines []string, dirs []BookDir, err error) {
+	lines = make([]string, 0)
+	dirs = make([]BookDir, 0)
 	// 读取txt文件
-	file, err := os.Open(path)
+	file, err := os.Open(config.BookPath(book.Title))
 	if err != nil {
 		return
 	}
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
-	// /第[一二三四五六七八九十百千万零〇0-9]+(章|卷)/
-	re := regexp.MustCompile(`第[一二三四五六七八九十百千万零〇0-9]+(章|卷)`)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
 	for scanner.Scan() {
-		line := scanner.Text()
-		bookAll = append(bookAll, line)
-		if re.MatchString(line) {
-			bookDirs = append(bookDirs, BookDir{name: line, start: cnt})
-		}
-		cnt++
+		lines = append(lines, scanner.Text())
 	}
 	err = scanner.Err()
+	if err != nil {
+		return
+	}
+
+	// 导入时已解析出目录(epub)且未指定规则的直接使用，否则按规则识别章节
+	if book.ChapterRule == ChapterRuleAuto {
+		dirs = dirsFromChapters(dao.GetChapters(book.ID))
+		if len(dirs) > 0 {
+			return
+		}
+	}
+	dirs, err = ChaptersByRule(lines, book.ChapterRule, book.ChapterRegex)
+	if err != nil {
+		// 自定义正则失效时退回自动识别
+		dirs, _ = DetectChapters(lines)
+		err = nil
+	}
 	return
 }
 
-func GetBookContent(lastPos int) (title string, content string, bookCurrentIndex int) {
-	if len(bookDirs) > 1 && lastPos < bookDirs[0].start {
-		// 第0章，没有章节名,从0开始到第一章起点的前一行
-		title, content = "", strings.Join(bookAll[0:bookDirs[0].start-1], "\n")
-		bookCurrentIndex = -1
-		return
-	}
-	for i := 0; i < len(bookDirs); i++ {
-		if lastPos >= bookDirs[i].start {
-			start := bookDirs[i].start
-			if i == len(bookDirs)-1 {
-				// 最后一章
-				bookCurrentIndex = i
-				title = bookDirs[i].name
-				content = strings.Join(bookAll[start+1:], "\n")
-				return
-			}
-			if lastPos < bookDirs[i+1].start {
-				bookCurrentIndex = i
-				title = bookDirs[i].name
-				if start+1 >= bookDirs[i+1].start-1 {
-					content = ""
-					return
-				}
-				content = strings.Join(bookAll[start+1:bookDirs[i+1].start], "\n")
-				return
-			}
+// SetChapterRule 保存当前书的章节规则并重新识别章节
+func SetChapterRule(rule string, pattern string) (err error) {
+	dirs, err := ChaptersByRule(bookAll, rule, pattern)
+	if err != nil {
+		return
+	}
+	if rule == ChapterRuleAuto {
+		// 自动模式下优先使用导入时解析出的目录
+		if chapters := dao.GetChapters(bookID); len(chapters) > 0 {
+			dirs = dirsFromChapters(chapters)
 		}
 	}
-	return "", "", -1
+	err = dao.UpdateBookChapterRule(bookName, rule, pattern)
+	if err != nil {
+		return
+	}
+	bookRule, bookRegex = rule, pattern
+	bookDirs = dirs
+	return
+}
+
+// SetBookConvert 保存当前书阅读时的简繁转换
+func SetBookConvert(convert string) error {
+	if err := dao.UpdateBookConvert(bookName, convert); err != nil {
+		return err
+	}
+	bookConvert = convert
+	return nil
+}
+
+// GetBookContent 返回lastPos所在章节的章节名和内容(不含章节名所在行)
+func GetBookContent(lastPos int) (title string, content string, bookCurrentIndex int) {
+	bookCurrentIndex = GetChapterIndex(lastPos)
+	if bookCurrentIndex >= 0 {
+		title = bookDirs[bookCurrentIndex].name
+	}
+	// 第0章(没有章节名)从第0行开始到第一章的前一行
+	start, end := GetChapterStart(bookCurrentIndex)+1, GetChapterStart(bookCurrentIndex+1)
+	if start < end {
+		content = strings.Join(bookAll[start:end], "\n")
+	}
+	return
+}
+
+// GetBookDirs 返回当前打开的书的目录
+func GetBookDirs() []BookDir {
+	return bookDirs
+}
+
+// BookProgress 返回阅读进度百分比
+func BookProgress(book dao.Book) int {
+	if book.Length <= 1 {
+		return 100
+	}
+	progress := book.LastPos * 100 / (book.Length - 1)
+	if progress > 100 {
+		progress = 100
+	}
+	return progress
+}
+
+// GetChapterIndex 返回pos所在章节的下标，在第一章之前时为-1
+func GetChapterIndex(pos int) int {
+	return chapterIndex(bookDirs, pos)
+}
+
+func chapterIndex(dirs []BookDir, pos int) int {
+	return sort.Search(len(dirs), func(i int) bool { return dirs[i].start > pos }) - 1
+}
+
+// GetChapterPath 返回 "卷 › 章" 形式的章节名
+func GetChapterPath(chapterIndex int) string {
+	return chapterPath(bookDirs, chapterIndex)
+}
+
+func chapterPath(dirs []BookDir, index int) string {
+	if index < 0 || index >= len(dirs) {
+		return ""
+	}
+	dir := dirs[index]
+	if dir.parent >= 0 {
+		return dirs[dir.parent].name + " › " + dir.name
+	}
+	return dir.name
 }
 
+// GetChapterStart 返回章节名所在行，第0章没有章节名，返回-1
 func GetChapterStart(chapterIndex int) int {
 	if chapterIndex < 0 {
-		return 0
+		return -1
 	}
 	if chapterIndex >= len(bookDirs) {
 		return len(bookAll)
@@ -192,7 +408,22 @@ func GetChapterStart(chapterIndex int) int {
 	return bookDirs[chapterIndex].start
 }
 
+// AddBookmark 在当前阅读位置添加书签
+func AddBookmark(chapterIndex int) (dao.Bookmark, error) {
+	snippet := ""
+	if bookPos >= 0 && bookPos < len(bookAll) {
+		snippet = runewidth.Truncate(strings.TrimSpace(bookAll[bookPos]), _snippetWidth, "…")
+	}
+	return dao.CreateBookmark(dao.Bookmark{
+		BookID:  bookID,
+		Pos:     bookPos,
+		Chapter: GetChapterPath(chapterIndex),
+		Snippet: snippet,
+	})
+}
+
 func UpdateBookPos(name string, pos int) {
+	bookPos = pos
 	// 防抖
 	updateBookPosDebounce.Debounce(func() {
 		dao.UpdateBookPos(name, pos)
@@ -205,7 +436,7 @@ func DelBook(name string) error {
 		return err
 	}
 	// 删除文件
-	os.Remove("download/" + name + ".txt")
+	os.Remove(config.BookPath(name))
 	return nil
 }
 
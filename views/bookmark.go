@@ -0,0 +1,177 @@
+package views
+
+import (
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type bookmarkMsg struct{}
+
+func bookmarkCmd() tea.Cmd {
+	return func() tea.Msg {
+		return bookmarkMsg{}
+	}
+}
+
+type keyMapBookmark struct {
+	Back   key.Binding
+	Select key.Binding
+	Rename key.Binding
+	Remove key.Binding
+}
+
+var _keysBookmark = keyMapBookmark{
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q", "B"),
+		key.WithHelp("q", "back"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "jump"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "rename"),
+	),
+	Remove: key.NewBinding(
+		key.WithKeys("r", "delete"),
+		key.WithHelp("r", "remove"),
+	),
+}
+
+type itemBookmark dao.Bookmark
+
+func (i itemBookmark) Title() string {
+	if i.Label != "" {
+		return i.Label
+	}
+	return i.Chapter
+}
+func (i itemBookmark) Description() string {
+	return i.CreatedAt.Format("2006-01-02 15:04") + "  " + i.Snippet
+}
+func (i itemBookmark) FilterValue() string { return i.Label + i.Chapter + i.Snippet }
+
+type modelBookmark struct {
+	list  list.Model
+	input textinput.Model
+}
+
+func (m modelBookmark) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelBookmark) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := _docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v-2)
+		return m, nil
+	case tea.KeyMsg:
+		if m.input.Focused() {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+				m.input.Blur()
+				_typing = false
+				return m, nil
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+				m.input.Blur()
+				_typing = false
+				item, ok := m.list.SelectedItem().(itemBookmark)
+				if !ok {
+					return m, nil
+				}
+				if err := dao.UpdateBookmarkLabel(item.ID, m.input.Value()); err != nil {
+					return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Rename Failed", Confirm: "OK"})
+				}
+				return m, bookmarkCmd()
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if key.Matches(msg, _keysBookmark.Back) {
+			return m, viewCmd(viewPager)
+		}
+		item, ok := m.list.SelectedItem().(itemBookmark)
+		if !ok {
+			break
+		}
+		switch {
+		case key.Matches(msg, _keysBookmark.Select):
+			title, content, index := GetBookContent(item.Pos)
+			return m, tea.Batch(
+				pagerCmd(pagerMsg{title: title, content: content, lastPos: item.Pos, currentIndex: index}),
+				viewCmd(viewPager),
+			)
+		case key.Matches(msg, _keysBookmark.Rename):
+			m.input.SetValue(item.Label)
+			m.input.CursorEnd()
+			_typing = true
+			return m, m.input.Focus()
+		case key.Matches(msg, _keysBookmark.Remove):
+			return m, dialogCmd(dialogMsg{
+				Type:    DialogDefault,
+				Title:   "Delete bookmark " + item.Title() + "?",
+				Confirm: "Delete",
+				Cancel:  "Cancel",
+				ConfirmFunc: func() tea.Cmd {
+					if err := dao.DeleteBookmark(item.ID); err != nil {
+						return dialogCmd(dialogMsg{Type: DialogAlert, Title: "Delete Failed", Confirm: "OK"})
+					}
+					return tea.Batch(dialogCmd(dialogMsg{Type: DialogNone}), bookmarkCmd())
+				},
+			})
+		}
+	case bookmarkMsg:
+		items := []list.Item{}
+		for _, bookmark := range dao.GetBookmarks(bookID) {
+			items = append(items, itemBookmark(bookmark))
+		}
+		index := m.list.Index()
+		cmd = m.list.SetItems(items)
+		if index >= len(items) {
+			index = len(items) - 1
+		}
+		m.list.Select(index)
+		return m, cmd
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		_typing = m.list.FilterState() == list.Filtering
+	}
+	return m, cmd
+}
+
+func (m modelBookmark) View() string {
+	if m.input.Focused() {
+		return _docStyle.Render(m.list.View() + "\n" + m.input.View())
+	}
+	return _docStyle.Render(m.list.View() + "\n")
+}
+
+func NewBookmark() modelBookmark {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Bookmarks"
+	l.Styles.Title = titleStyle
+	l.SetStatusBarItemName("bookmark", "bookmarks")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysBookmark.Select, _keysBookmark.Rename, _keysBookmark.Remove}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysBookmark.Select, _keysBookmark.Rename, _keysBookmark.Remove}
+	}
+
+	input := textinput.New()
+	input.Prompt = "label: "
+
+	return modelBookmark{list: l, input: input}
+}
@@ -0,0 +1,195 @@
+package views
+
+import (
+	"errors"
+	"go-reader/dao"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ChapterRule 章节识别规则，匹配Pattern的行中同时匹配Volume的作为卷
+type ChapterRule struct {
+	Name    string
+	Pattern string
+	Volume  string
+	re      *regexp.Regexp
+	volume  *regexp.Regexp
+}
+
+const (
+	ChapterRuleAuto   = ""       // 自动选择
+	ChapterRuleCustom = "custom" // 用户自定义正则
+)
+
+const (
+	_chapterTitleMaxWidth = 60 // 超过该宽度的行不会被识别为章节名
+	_chapterMinLines      = 5  // 自动识别时每章的最少行数
+)
+
+// 内置规则，自动识别时按顺序选择第一个章节数合理的规则
+var ChapterRules = []ChapterRule{
+	// 章节名在行首，后面是空白、标点或行尾，避免"第三节课"、"后记得"这样的正文
+	{
+		Name:    "chinese",
+		Pattern: `^\s*(第[一二三四五六七八九十百千万零〇两0-9０-９]+[章卷回节集部篇]|序章|序言|楔子|引子|尾声|后记|番外[一二三四五六七八九十0-9０-９]*)([\s\p{Z}\p{P}]|$)`,
+		Volume:  `^\s*第[一二三四五六七八九十百千万零〇两0-9０-９]+[卷部集篇]`,
+	},
+	{
+		Name:    "english",
+		Pattern: `(?i)^\s*((chapter|part|book|volume)\s+([0-9]+|[ivxlcdm]+|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|[a-z]+teen|(twenty|thirty|forty|fifty|sixty|seventy|eighty|ninety)(-[a-z]+)?)\b|(prologue|epilogue|interlude|preface|introduction|afterword)\b)`,
+		Volume:  `(?i)^\s*(part|book|volume)\s`,
+	},
+	{
+		Name:    "markdown",
+		Pattern: `^#{1,6}\s+\S`,
+		Volume:  `^#\s`,
+	},
+	{
+		Name:    "roman",
+		Pattern: `^\s*[IVXLCDM]+\s*([.:、．]\s*\S.*)?$`,
+	},
+	{
+		Name:    "numbered",
+		Pattern: `^\s*[0-9０-９]{1,4}\s*[.、．:：]`,
+	},
+}
+
+func init() {
+	for i := range ChapterRules {
+		ChapterRules[i].re = regexp.MustCompile(ChapterRules[i].Pattern)
+		if ChapterRules[i].Volume != "" {
+			ChapterRules[i].volume = regexp.MustCompile(ChapterRules[i].Volume)
+		}
+	}
+}
+
+func GetChapterRule(name string) (rule ChapterRule, ok bool) {
+	for _, rule = range ChapterRules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return ChapterRule{}, false
+}
+
+// MatchChapters 按规则匹配章节名
+func MatchChapters(lines []string, rule ChapterRule) []BookDir {
+	dirs := make([]BookDir, 0)
+	for i, line := range lines {
+		if runewidth.StringWidth(line) > _chapterTitleMaxWidth {
+			continue
+		}
+		if rule.re.MatchString(line) {
+			volume := rule.volume != nil && rule.volume.MatchString(line)
+			dirs = append(dirs, BookDir{name: line, start: i, volume: volume})
+		}
+	}
+	return linkDirs(dirs)
+}
+
+// linkDirs 建立卷与章节的父子关系，全部是卷或没有卷时按平级处理
+func linkDirs(dirs []BookDir) []BookDir {
+	volumes, chapters := 0, 0
+	for _, dir := range dirs {
+		if dir.volume {
+			volumes++
+		} else {
+			chapters++
+		}
+	}
+	parent := -1
+	for i := range dirs {
+		dirs[i].children = nil
+		if volumes == 0 || chapters == 0 {
+			dirs[i].volume = false
+		}
+		if dirs[i].volume {
+			parent = i
+			dirs[i].parent = -1
+			continue
+		}
+		dirs[i].parent = parent
+		if parent >= 0 {
+			dirs[parent].children = append(dirs[parent].children, i)
+		}
+	}
+	return dirs
+}
+
+func dirsFromChapters(chapters []dao.Chapter) []BookDir {
+	dirs := make([]BookDir, 0, len(chapters))
+	for _, chapter := range chapters {
+		dirs = append(dirs, BookDir{name: chapter.Title, start: chapter.Start, volume: chapter.Volume})
+	}
+	return linkDirs(dirs)
+}
+
+// DetectChapters 返回第一个章节数合理的内置规则的识别结果
+func DetectChapters(lines []string) (dirs []BookDir, ruleName string) {
+	for _, rule := range ChapterRules {
+		found := MatchChapters(lines, rule)
+		if chaptersReasonable(found, len(lines)) {
+			return found, rule.Name
+		}
+	}
+	return []BookDir{}, ChapterRuleAuto
+}
+
+// chaptersReasonable 至少两章，平均每章和章节间距的中位数都不少于_chapterMinLines行
+// 误识别的正文往往挨在一起，只看平均值时会被几个很长的章节掩盖
+func chaptersReasonable(dirs []BookDir, total int) bool {
+	if len(dirs) < 2 || total/len(dirs) < _chapterMinLines {
+		return false
+	}
+	gaps := make([]int, 0, len(dirs))
+	for i, dir := range dirs {
+		if dir.volume {
+			continue
+		}
+		end := total
+		if i+1 < len(dirs) {
+			end = dirs[i+1].start
+		}
+		gaps = append(gaps, end-dir.start)
+	}
+	if len(gaps) == 0 {
+		return true
+	}
+	sort.Ints(gaps)
+	return gaps[len(gaps)/2] >= _chapterMinLines
+}
+
+// compileChapterPattern 编译自定义章节正则，能匹配空字符串的正则会把每一行都当作章节，不允许使用
+func compileChapterPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New("chapter pattern is empty")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, errors.New("chapter pattern matches empty text: " + pattern)
+	}
+	return re, nil
+}
+
+// ChaptersByRule 按用户选择的规则识别章节，rule为ChapterRuleCustom时使用pattern
+func ChaptersByRule(lines []string, rule string, pattern string) (dirs []BookDir, err error) {
+	if rule == ChapterRuleCustom {
+		var re *regexp.Regexp
+		re, err = compileChapterPattern(pattern)
+		if err != nil {
+			return
+		}
+		return MatchChapters(lines, ChapterRule{Name: ChapterRuleCustom, Pattern: pattern, re: re}), nil
+	}
+	if r, ok := GetChapterRule(rule); ok {
+		return MatchChapters(lines, r), nil
+	}
+	dirs, _ = DetectChapters(lines)
+	return
+}
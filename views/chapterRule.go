@@ -0,0 +1,159 @@
+package views
+
+import (
+	"fmt"
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type ruleMsg struct{}
+
+func ruleCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ruleMsg{}
+	}
+}
+
+type keyMapRule struct {
+	Back   key.Binding
+	Select key.Binding
+}
+
+var _keysRule = keyMapRule{
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("q", "back"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "apply"),
+	),
+}
+
+type itemRule struct {
+	rule, title, desc string
+}
+
+func (i itemRule) Title() string       { return i.title }
+func (i itemRule) Description() string { return i.desc }
+func (i itemRule) FilterValue() string { return i.title }
+
+type modelRule struct {
+	list  list.Model
+	input textinput.Model
+}
+
+func (m modelRule) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelRule) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := _docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v-2)
+		return m, nil
+	case tea.KeyMsg:
+		if m.input.Focused() {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+				m.input.Blur()
+				_typing = false
+				return m, nil
+			case key.Matches(msg, _keysRule.Select):
+				return m, m.apply(ChapterRuleCustom, m.input.Value())
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		switch {
+		case key.Matches(msg, _keysRule.Back):
+			return m, viewCmd(viewDirList)
+		case key.Matches(msg, _keysRule.Select):
+			item, ok := m.list.SelectedItem().(itemRule)
+			if !ok {
+				return m, nil
+			}
+			if item.rule == ChapterRuleCustom {
+				_typing = true
+				return m, m.input.Focus()
+			}
+			return m, m.apply(item.rule, "")
+		}
+	case ruleMsg:
+		m.input.SetValue(bookRegex)
+		m.input.Blur()
+		cmd = m.list.SetItems(getRuleItems())
+		for i, item := range m.list.Items() {
+			if item.(itemRule).rule == bookRule {
+				m.list.Select(i)
+			}
+		}
+		return m, cmd
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// apply 保存规则，回到目录并重新定位当前阅读位置
+func (m modelRule) apply(rule string, pattern string) tea.Cmd {
+	_typing = false
+	if err := SetChapterRule(rule, pattern); err != nil {
+		return dialogCmd(dialogMsg{Type: DialogAlert, Title: err.Error(), Confirm: "OK"})
+	}
+	title, content, index := GetBookContent(bookPos)
+	return tea.Batch(
+		pagerCmd(pagerMsg{title: title, content: content, lastPos: bookPos, currentIndex: index}),
+		dirCmd(DirMsg{index: index}),
+		viewCmd(viewDirList),
+	)
+}
+
+func (m modelRule) View() string {
+	return _docStyle.Render(m.list.View() + "\n" + m.input.View())
+}
+
+func getRuleItems() []list.Item {
+	_, detected := DetectChapters(bookAll)
+	if detected == ChapterRuleAuto {
+		detected = "none"
+	}
+	if len(dao.GetChapters(bookID)) > 0 {
+		detected = "table of contents"
+	}
+	items := []list.Item{itemRule{rule: ChapterRuleAuto, title: "Auto", desc: "detected: " + detected}}
+	for _, rule := range ChapterRules {
+		items = append(items, itemRule{
+			rule:  rule.Name,
+			title: rule.Name,
+			desc:  fmt.Sprintf("%d chapters", len(MatchChapters(bookAll, rule))),
+		})
+	}
+	desc := "type a regular expression"
+	if bookRegex != "" {
+		desc = bookRegex
+	}
+	return append(items, itemRule{rule: ChapterRuleCustom, title: "Custom", desc: desc})
+}
+
+func NewRule() modelRule {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Chapter Rule"
+	l.Styles.Title = titleStyle
+	l.SetFilteringEnabled(false)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysRule.Select}
+	}
+
+	input := textinput.New()
+	input.Prompt = "regex: "
+	input.Placeholder = `^Chapter \d+`
+
+	return modelRule{list: l, input: input}
+}
@@ -0,0 +1,122 @@
+package views
+
+import (
+	"fmt"
+	"testing"
+)
+
+// 请求中列出的各种章节名都要被对应的规则识别
+func TestChapterRules(t *testing.T) {
+	tests := []struct {
+		rule  string
+		line  string
+		match bool
+	}{
+		{"chinese", "第一章 开始", true},
+		{"chinese", "第12回 结束", true},
+		{"chinese", "第一卷", true},
+		{"chinese", "楔子", true},
+		{"chinese", "正文", false},
+		{"chinese", "  第一章：开始", true},
+		{"chinese", "第一章\u3000标题", true},
+		{"chinese", "第二集", true},
+		{"chinese", "后记", true},
+		{"chinese", "番外二 结局", true},
+		// 正文中提到章节的短句不是章节名
+		{"chinese", "他说第三章写得好，然后走了。", false},
+		{"chinese", "第三节课下课了", false},
+		{"chinese", "这是第一部分的内容", false},
+		{"chinese", "第一部分", false},
+		{"chinese", "第二集里他终于出场", false},
+		{"chinese", "后记得带伞", false},
+		{"chinese", "尾声渐起", false},
+		{"english", "Chapter 12", true},
+		{"english", "CHAPTER XII", true},
+		{"english", "Chapter Twenty-One", true},
+		{"english", "Part One", true},
+		{"english", "Prologue", true},
+		{"english", "chapters are long", false},
+		{"markdown", "## One", true},
+		{"markdown", "#no space", false},
+		{"roman", "IV", true},
+		{"roman", "II. The Return", true},
+		{"roman", "Ivan", false},
+		{"numbered", "001.", true},
+		{"numbered", "12、Twelve", true},
+		{"numbered", "２：全角", true},
+		{"numbered", "12345. too long", false},
+	}
+	for _, tt := range tests {
+		rule, ok := GetChapterRule(tt.rule)
+		if !ok {
+			t.Fatalf("no rule %q", tt.rule)
+		}
+		if got := rule.re.MatchString(tt.line); got != tt.match {
+			t.Errorf("%s rule on %q = %v, want %v", tt.rule, tt.line, got, tt.match)
+		}
+	}
+}
+
+// testBook 生成n章、每章per行正文的书
+func testBook(title string, n int, per int) []string {
+	lines := make([]string, 0, n*(per+1))
+	for i := 1; i <= n; i++ {
+		lines = append(lines, fmt.Sprintf(title, i))
+		for j := 0; j < per; j++ {
+			lines = append(lines, "正文内容。")
+		}
+	}
+	return lines
+}
+
+func TestDetectChapters(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		wantRule string
+		wantDirs int
+	}{
+		{"chinese", testBook("第%d章", 5, 10), "chinese", 5},
+		{"english", testBook("Chapter %d", 4, 10), "english", 4},
+		{"markdown", testBook("## Title %d", 3, 10), "markdown", 3},
+		// 平均每章不足_chapterMinLines行时不采用
+		{"too dense", testBook("Chapter %d", 10, 1), ChapterRuleAuto, 0},
+		{"single", testBook("Chapter %d", 1, 10), ChapterRuleAuto, 0},
+		// 平均值足够，但大部分章节名挨在一起
+		{"clustered", append(testBook("第%d章", 2, 40), testBook("第%d章", 6, 0)...), ChapterRuleAuto, 0},
+		// 开头的目录不影响识别
+		{"with toc", append(testBook("第%d章", 6, 0), testBook("第%d章", 6, 10)...), "chinese", 12},
+	}
+	for _, tt := range tests {
+		dirs, rule := DetectChapters(tt.lines)
+		if rule != tt.wantRule || len(dirs) != tt.wantDirs {
+			t.Errorf("%s: DetectChapters = %d dirs by %q, want %d by %q", tt.name, len(dirs), rule, tt.wantDirs, tt.wantRule)
+		}
+	}
+}
+
+func TestMatchChaptersSkipsLongLines(t *testing.T) {
+	rule, _ := GetChapterRule("english")
+	long := fmt.Sprintf("Chapter 1 %*s", _chapterTitleMaxWidth, "x")
+	dirs := MatchChapters([]string{"Chapter 2", long}, rule)
+	if len(dirs) != 1 || dirs[0].name != "Chapter 2" {
+		t.Errorf("MatchChapters = %+v, want only the short title", dirs)
+	}
+}
+
+func TestChaptersByRuleCustom(t *testing.T) {
+	lines := []string{"=== A ===", "text", "=== B ===", "text"}
+	dirs, err := ChaptersByRule(lines, ChapterRuleCustom, `^=== .* ===$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || dirs[0].start != 0 || dirs[1].start != 2 {
+		t.Errorf("ChaptersByRule = %+v", dirs)
+	}
+	// 空的或能匹配空字符串的正则会把每一行都当作章节，保存前拒绝
+	for _, pattern := range []string{`(`, "", "  \t", `x*`, `^`} {
+		if _, err := ChaptersByRule(lines, ChapterRuleCustom, pattern); err == nil {
+			t.Errorf("ChaptersByRule accepted the pattern %q", pattern)
+		}
+	}
+}
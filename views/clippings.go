@@ -0,0 +1,583 @@
+package views
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go-reader/dao"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// 导入的标注来源
+const (
+	SourceKindle   = "kindle"
+	SourceKOReader = "koreader"
+)
+
+const (
+	_kindleSeparator = "=========="
+	_matchTitleMin   = 0.75 // 书名相似度的最小值
+	_anchorRunes     = 12   // 模糊定位时用于查找的开头、结尾的字数
+)
+
+var (
+	// 书名 (作者)
+	_kindleTitle    = regexp.MustCompile(`^(.*?)\s*[(（]([^()（）]*)[)）]\s*$`)
+	_kindleLocation = regexp.MustCompile(`(?i)(?:location|loc\.|位置)\s*#?\s*(\d+)(?:\s*-\s*(\d+))?`)
+	_kindleAdded    = regexp.MustCompile(`(?i)Added on\s+(.*)$`)
+	_kindleNote     = regexp.MustCompile(`(?i)\bnote\b|笔记|メモ`)
+	_kindleBookmark = regexp.MustCompile(`(?i)\bbookmark\b|书签|ブックマーク`)
+	_kindleLayouts  = []string{
+		"Monday, January 2, 2006 3:04:05 PM",
+		"Monday, 2 January 2006 15:04:05",
+		"Monday, January 2, 2006, 3:04 PM",
+	}
+)
+
+// Clipping 从其他阅读器导出的一条标注
+type Clipping struct {
+	Title  string
+	Author string
+	Text   string
+	Note   string
+	Added  time.Time
+	Source string
+	// Kindle的位置，用于对应笔记和标注
+	locStart, locEnd int
+}
+
+// UnmatchedClipping 无法导入的标注及原因
+type UnmatchedClipping struct {
+	Clipping
+	Reason string
+}
+
+// Snippet 摘录的开头，只有笔记时为笔记
+func (u UnmatchedClipping) Snippet() string {
+	text := u.Text
+	if text == "" {
+		text = u.Note
+	}
+	return runewidth.Truncate(strings.ReplaceAll(text, "\n", " "), _snippetWidth, "…")
+}
+
+// ClippingsReport 导入标注的结果
+type ClippingsReport struct {
+	Imported   int
+	Duplicates int
+	Unmatched  []UnmatchedClipping
+}
+
+// Summary 一句话的导入结果
+func (r ClippingsReport) Summary() string {
+	s := "Imported " + strconv.Itoa(r.Imported) + " highlights"
+	if r.Duplicates > 0 {
+		s += ", " + strconv.Itoa(r.Duplicates) + " already imported"
+	}
+	if len(r.Unmatched) > 0 {
+		s += ", " + strconv.Itoa(len(r.Unmatched)) + " unmatched"
+	}
+	return s
+}
+
+// IsClippingsFile 是否为Kindle的My Clippings.txt或KOReader的metadata.lua
+func IsClippingsFile(path string) bool {
+	return strings.EqualFold(filepath.Base(path), "My Clippings.txt") || isKOReaderMetadata(path)
+}
+
+// ParseClippings 解析文件，目录中查找所有的My Clippings.txt和*.sdr/metadata.*.lua
+func ParseClippings(path string) ([]Clipping, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if isKOReaderMetadata(path) {
+			return parseKOReader(path)
+		}
+		return parseKindleClippings(path)
+	}
+	clippings := make([]Clipping, 0)
+	found := false
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !IsClippingsFile(file) {
+			return err
+		}
+		found = true
+		parsed, err := ParseClippings(file)
+		if err != nil {
+			return err
+		}
+		clippings = append(clippings, parsed...)
+		return nil
+	})
+	if err == nil && !found {
+		err = errors.New("no My Clippings.txt or KOReader metadata found in " + path)
+	}
+	return clippings, err
+}
+
+// parseKindleClippings 解析Kindle的My Clippings.txt，每条以==========分隔:
+// 书名 (作者)
+// - Your Highlight on page 1 | Location 10-12 | Added on Monday, March 5, 2018 10:43:21 PM
+//
+// 内容
+func parseKindleClippings(path string) ([]Clipping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	highlights := make([]Clipping, 0)
+	notes := make([]Clipping, 0)
+	for _, entry := range strings.Split(content, _kindleSeparator) {
+		lines := strings.Split(strings.TrimLeft(entry, "\n"), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		header := strings.TrimSpace(strings.TrimPrefix(lines[0], "\ufeff"))
+		meta := lines[1]
+		if header == "" || _kindleBookmark.MatchString(meta) {
+			continue
+		}
+		c := Clipping{Title: header, Source: SourceKindle}
+		if m := _kindleTitle.FindStringSubmatch(header); m != nil && m[1] != "" {
+			c.Title, c.Author = m[1], strings.TrimSpace(m[2])
+		}
+		if m := _kindleLocation.FindStringSubmatch(meta); m != nil {
+			c.locStart, _ = strconv.Atoi(m[1])
+			c.locEnd = c.locStart
+			if m[2] != "" {
+				c.locEnd, _ = strconv.Atoi(m[2])
+			}
+		}
+		if m := _kindleAdded.FindStringSubmatch(meta); m != nil {
+			for _, layout := range _kindleLayouts {
+				if added, err := time.ParseInLocation(layout, strings.TrimSpace(m[1]), time.Local); err == nil {
+					c.Added = added
+					break
+				}
+			}
+		}
+		text := strings.TrimSpace(strings.Join(lines[2:], "\n"))
+		if text == "" {
+			continue
+		}
+		if _kindleNote.MatchString(meta) {
+			c.Note = text
+			notes = append(notes, c)
+			continue
+		}
+		c.Text = text
+		// 修改过的标注会再添加一条，保留最后一条
+		if n := len(highlights); n > 0 && highlights[n-1].Title == c.Title && c.locStart > 0 &&
+			highlights[n-1].locStart == c.locStart && strings.HasPrefix(c.Text, highlights[n-1].Text) {
+			highlights[n-1] = c
+			continue
+		}
+		highlights = append(highlights, c)
+	}
+	// 笔记附加到位置包含它的标注上
+	for _, note := range notes {
+		attached := false
+		for i := len(highlights) - 1; i >= 0; i-- {
+			h := &highlights[i]
+			if h.Title == note.Title && note.locStart > 0 && h.locStart <= note.locStart && note.locStart <= h.locEnd {
+				if h.Note != "" {
+					h.Note += "\n"
+				}
+				h.Note += note.Note
+				attached = true
+				break
+			}
+		}
+		if !attached {
+			highlights = append(highlights, note)
+		}
+	}
+	return highlights, nil
+}
+
+// normalizeForMatch 只保留字母和数字并转为小写，忽略空白、标点的差异
+func normalizeForMatch(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// similarity 两个字符串的编辑距离相似度，0-1
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// titleVariants 书名及去掉副标题、扩展名后的形式
+func titleVariants(title string) []string {
+	variants := []string{title}
+	if ext := filepath.Ext(title); ext != "" && len(ext) <= 5 {
+		title = strings.TrimSuffix(title, ext)
+		variants = append(variants, title)
+	}
+	for _, sep := range []string{":", "：", " - ", "——", "("} {
+		if i := strings.Index(title, sep); i > 0 {
+			variants = append(variants, title[:i])
+		}
+	}
+	normalized := make([]string, 0, len(variants))
+	for _, v := range variants {
+		if v = normalizeForMatch(v); v != "" {
+			normalized = append(normalized, v)
+		}
+	}
+	return normalized
+}
+
+// titleScore 书名的相似度，包含关系时按长度比例计算
+func titleScore(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	short, long := a, b
+	if utf8.RuneCountInString(short) > utf8.RuneCountInString(long) {
+		short, long = long, short
+	}
+	score := similarity(a, b)
+	if strings.Contains(long, short) {
+		score = max(score, 0.6+0.4*float64(utf8.RuneCountInString(short))/float64(utf8.RuneCountInString(long)))
+	}
+	return score
+}
+
+// matchBook 按书名模糊匹配书架中的书，作者相同时优先
+func matchBook(c Clipping, books []dao.Book) (dao.Book, bool) {
+	best, bestScore := dao.Book{}, 0.0
+	author := normalizeForMatch(c.Author)
+	for _, book := range books {
+		score := 0.0
+		for _, a := range titleVariants(c.Title) {
+			for _, b := range titleVariants(book.Title) {
+				score = max(score, titleScore(a, b))
+			}
+		}
+		if bookAuthor := normalizeForMatch(book.Author); author != "" && bookAuthor != "" &&
+			(strings.Contains(author, bookAuthor) || strings.Contains(bookAuthor, author)) {
+			score += 0.1
+		}
+		if score > bestScore {
+			best, bestScore = book, score
+		}
+	}
+	return best, bestScore >= _matchTitleMin
+}
+
+// textIndex 用于在书中查找摘录，norm为normalizeForMatch后的全文
+type textIndex struct {
+	norm   string
+	offset []int  // 每个字在norm中的字节偏移
+	line   []int  // 每个字所在行
+	col    []int  // 每个字在行内的字节偏移
+	size   []int8 // 每个字在原文中的字节数
+}
+
+func newTextIndex(lines []string) *textIndex {
+	idx := &textIndex{}
+	var sb strings.Builder
+	for i, line := range lines {
+		for col, r := range line {
+			if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+				continue
+			}
+			idx.offset = append(idx.offset, sb.Len())
+			idx.line = append(idx.line, i)
+			idx.col = append(idx.col, col)
+			idx.size = append(idx.size, int8(utf8.RuneLen(r)))
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	idx.norm = sb.String()
+	return idx
+}
+
+// runeAt norm中字节偏移对应的字
+func (idx *textIndex) runeAt(offset int) int {
+	return sort.SearchInts(idx.offset, offset)
+}
+
+// indexAll s在norm中所有出现的位置(字)
+func (idx *textIndex) indexAll(s string) []int {
+	found := make([]int, 0)
+	for from := 0; ; {
+		i := strings.Index(idx.norm[from:], s)
+		if i < 0 {
+			return found
+		}
+		found = append(found, idx.runeAt(from+i))
+		from += i + len(s)
+	}
+}
+
+// find 定位摘录，找不到完全相同的文字时用开头、结尾和中间的几个字定位
+func (idx *textIndex) find(text string) (startLine, startCol, endLine, endCol int, ok bool) {
+	norm := []rune(normalizeForMatch(text))
+	n := len(norm)
+	if n == 0 {
+		return
+	}
+	start, end := -1, -1
+	if found := idx.indexAll(string(norm)); len(found) > 0 {
+		start, end = found[0], found[0]+n
+	} else if n >= _anchorRunes*2 {
+		head, tail := idx.indexAll(string(norm[:_anchorRunes])), idx.indexAll(string(norm[n-_anchorRunes:]))
+		// 开头和结尾之间的距离与摘录长度相近
+		for _, h := range head {
+			j := sort.SearchInts(tail, h+_anchorRunes)
+			if j < len(tail) && tail[j]+_anchorRunes-h <= n*3/2 {
+				start, end = h, tail[j]+_anchorRunes
+				break
+			}
+		}
+		// 只找到一处时，用编辑距离确定另一端
+		switch {
+		case start >= 0:
+		case len(head) > 0:
+			start = head[0]
+			end = start + alignPrefix(norm, idx.runes(start, start+n*3/2))
+		case len(tail) > 0:
+			end = tail[0] + _anchorRunes
+			start = end - alignPrefix(reverseRunes(norm), reverseRunes(idx.runes(end-n*3/2, end)))
+		default:
+			if mid := idx.indexAll(string(norm[n/2-_anchorRunes/2 : n/2+_anchorRunes/2])); len(mid) > 0 {
+				start = max(mid[0]-(n/2-_anchorRunes/2), 0)
+				end = start + alignPrefix(norm, idx.runes(start, start+n*3/2))
+			}
+		}
+	}
+	if start < 0 && end < 0 {
+		return
+	}
+	start, end = max(start, 0), min(end, len(idx.line))
+	if start >= end {
+		return
+	}
+	return idx.line[start], idx.col[start], idx.line[end-1], idx.col[end-1] + int(idx.size[end-1]), true
+}
+
+// extendPunct 摘录首尾的标点与原文相同时也包括在内
+func extendPunct(lines []string, text string, startLine, startCol, endLine, endCol int) (int, int) {
+	text = strings.TrimSpace(text)
+	first, _ := utf8.DecodeRuneInString(text)
+	if prev, size := utf8.DecodeLastRuneInString(lines[startLine][:startCol]); size > 0 && prev == first {
+		startCol -= size
+	}
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if next, size := utf8.DecodeRuneInString(lines[endLine][endCol:]); size > 0 && next == last {
+		endCol += size
+	}
+	return startCol, endCol
+}
+
+// runes norm中第from到第to个字，超出范围的部分被忽略
+func (idx *textIndex) runes(from, to int) []rune {
+	from, to = max(from, 0), min(to, len(idx.offset))
+	if from >= to {
+		return nil
+	}
+	end := len(idx.norm)
+	if to < len(idx.offset) {
+		end = idx.offset[to]
+	}
+	return []rune(idx.norm[idx.offset[from]:end])
+}
+
+// alignPrefix 与clip差异最小的text的前缀长度，差异过大时返回0
+func alignPrefix(clip []rune, text []rune) int {
+	// prev[j]为clip的前i个字与text的前j个字的编辑距离，替换记为2，避免多出的字被偶然对上而延长
+	prev := make([]int, len(text)+1)
+	cur := make([]int, len(text)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(clip); i++ {
+		cur[0] = i
+		for j := 1; j <= len(text); j++ {
+			cost := 2
+			if clip[i-1] == text[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	best := 0
+	for j := range prev {
+		if prev[j] < prev[best] {
+			best = j
+		}
+	}
+	if prev[best] > len(clip) {
+		return 0
+	}
+	return best
+}
+
+func reverseRunes(r []rune) []rune {
+	reversed := make([]rune, len(r))
+	for i, c := range r {
+		reversed[len(r)-1-i] = c
+	}
+	return reversed
+}
+
+// ImportClippings 导入Kindle、KOReader的标注到书架中对应的书
+func ImportClippings(paths []string) (report ClippingsReport, err error) {
+	clippings := make([]Clipping, 0)
+	for _, path := range paths {
+		parsed, err := ParseClippings(path)
+		if err != nil {
+			return report, err
+		}
+		clippings = append(clippings, parsed...)
+	}
+	books := dao.GetBooks()
+	type bookText struct {
+		lines    []string
+		index    *textIndex
+		existing []dao.Highlight
+	}
+	type bookMatch struct {
+		book dao.Book
+		ok   bool
+	}
+	matched := make(map[string]bookMatch)
+	texts := make(map[uint]*bookText)
+	for _, c := range clippings {
+		m, found := matched[c.Title]
+		if !found {
+			m.book, m.ok = matchBook(c, books)
+			matched[c.Title] = m
+		}
+		if !m.ok {
+			report.Unmatched = append(report.Unmatched, UnmatchedClipping{Clipping: c, Reason: "no matching book"})
+			continue
+		}
+		book := m.book
+		if c.Text == "" {
+			report.Unmatched = append(report.Unmatched, UnmatchedClipping{Clipping: c, Reason: "note without highlighted text"})
+			continue
+		}
+		text := texts[book.ID]
+		if text == nil {
+			lines, _, err := loadBook(book)
+			if err != nil {
+				return report, err
+			}
+			text = &bookText{lines: lines, index: newTextIndex(lines), existing: dao.GetHighlights(book.ID)}
+			texts[book.ID] = text
+		}
+		startLine, startCol, endLine, endCol, ok := text.index.find(c.Text)
+		if !ok {
+			report.Unmatched = append(report.Unmatched, UnmatchedClipping{Clipping: c, Reason: "passage not found in " + book.Title})
+			continue
+		}
+		startCol, endCol = extendPunct(text.lines, c.Text, startLine, startCol, endLine, endCol)
+		duplicate := false
+		for _, h := range text.existing {
+			if h.StartLine == startLine && h.StartCol == startCol && h.EndLine == endLine && h.EndCol == endCol {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			report.Duplicates++
+			continue
+		}
+		highlight, err := dao.CreateHighlight(dao.Highlight{
+			CreatedAt: c.Added,
+			BookID:    book.ID,
+			StartLine: startLine,
+			StartCol:  startCol,
+			EndLine:   endLine,
+			EndCol:    endCol,
+			Text:      rangeText(text.lines, startLine, startCol, endLine, endCol),
+			Note:      c.Note,
+			Source:    c.Source,
+		})
+		if err != nil {
+			return report, err
+		}
+		text.existing = append(text.existing, highlight)
+		report.Imported++
+	}
+	return report, nil
+}
+
+// clippingsDoneMsg 导入标注完成，highlights为后台重新读取的当前书的标注，在Update中替换
+type clippingsDoneMsg struct {
+	bookID     uint
+	highlights []dao.Highlight
+	dialog     dialogMsg
+}
+
+// importClippingsCmd 在导入界面选择标注文件时导入，结果显示在提示框中
+func importClippingsCmd(path string) tea.Cmd {
+	// 在界面的goroutine中读取，后台不访问当前书的全局变量
+	current := bookID
+	return func() tea.Msg {
+		report, err := ImportClippings([]string{path})
+		if err != nil {
+			return dialogMsg{Type: DialogAlert, Title: "Import Failed: " + err.Error(), Confirm: "OK"}
+		}
+		title := report.Summary()
+		if len(report.Unmatched) > 0 {
+			names := make([]string, 0)
+			seen := make(map[string]bool)
+			for _, u := range report.Unmatched {
+				if !seen[u.Title] {
+					seen[u.Title] = true
+					names = append(names, u.Title)
+				}
+			}
+			title += ": " + runewidth.Truncate(strings.Join(names, ", "), _snippetWidth, "…")
+		}
+		msg := clippingsDoneMsg{bookID: current, dialog: dialogMsg{Type: DialogAlert, Title: title, Confirm: "OK"}}
+		// 当前打开的书也要更新
+		if current != 0 && report.Imported > 0 {
+			msg.highlights = dao.GetHighlights(current)
+		}
+		return msg
+	}
+}
@@ -0,0 +1,123 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-reader/dao"
+)
+
+// writeTestFile 在临时目录中写入name，返回路径
+func writeTestFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// kindleEntry 一条My Clippings.txt记录，Kindle使用CRLF换行
+func kindleEntry(header, meta, text string) string {
+	return strings.Join([]string{header, "- " + meta, "", text, _kindleSeparator, ""}, "\r\n")
+}
+
+func TestParseKindleClippings(t *testing.T) {
+	path := writeTestFile(t, "My Clippings.txt", "\ufeff"+
+		kindleEntry("三体 (刘慈欣)", "Your Highlight on page 12 | Location 100-102 | Added on Monday, March 5, 2018 10:43:21 PM", "给岁月以文明")+
+		// 修改标注后Kindle会再追加一条，只保留最后一条
+		kindleEntry("三体 (刘慈欣)", "Your Highlight on page 12 | Location 100-103 | Added on Monday, March 5, 2018 10:44:00 PM", "给岁月以文明，而不是给文明以岁月")+
+		kindleEntry("三体 (刘慈欣)", "Your Note on page 12 | Location 101 | Added on Monday, March 5, 2018 10:45:00 PM", "名句")+
+		kindleEntry("三体 (刘慈欣)", "Your Bookmark on page 20 | Location 200 | Added on Monday, March 5, 2018 10:46:00 PM", "")+
+		kindleEntry("The Book (Some One)", "您在位置 #50-51的标注 | Added on Monday, 5 March 2018 22:43:21", "A line.")+
+		kindleEntry("No Author", "Your Note on Location 9 | Added on Monday, March 5, 2018, 9:00 PM", "lonely note"))
+	clippings, err := parseKindleClippings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clippings) != 3 {
+		t.Fatalf("got %d clippings, want 3: %+v", len(clippings), clippings)
+	}
+
+	edited := clippings[0]
+	if edited.Title != "三体" || edited.Author != "刘慈欣" || edited.Text != "给岁月以文明，而不是给文明以岁月" {
+		t.Errorf("edited highlight = %+v", edited)
+	}
+	if edited.Note != "名句" {
+		t.Errorf("note on location 101 attached as %q, want 名句", edited.Note)
+	}
+	if want := time.Date(2018, 3, 5, 22, 44, 0, 0, time.Local); !edited.Added.Equal(want) {
+		t.Errorf("Added = %v, want %v", edited.Added, want)
+	}
+
+	localized := clippings[1]
+	if localized.Author != "Some One" || localized.locStart != 50 || localized.locEnd != 51 ||
+		!localized.Added.Equal(time.Date(2018, 3, 5, 22, 43, 21, 0, time.Local)) {
+		t.Errorf("localized clipping = %+v", localized)
+	}
+
+	// 没有对应标注的笔记单独导入
+	if note := clippings[2]; note.Title != "No Author" || note.Author != "" || note.Text != "" || note.Note != "lonely note" {
+		t.Errorf("standalone note = %+v", note)
+	}
+}
+
+func TestMatchBook(t *testing.T) {
+	books := []dao.Book{
+		{ID: 1, Title: "三体", Author: "刘慈欣"},
+		{ID: 2, Title: "三体II：黑暗森林", Author: "刘慈欣"},
+		{ID: 3, Title: "Dune", Author: "Frank Herbert"},
+		{ID: 4, Title: "Dune", Author: "Someone Else"},
+	}
+	tests := []struct {
+		title, author string
+		want          uint // 0为找不到
+	}{
+		{"三体", "刘慈欣", 1},
+		{"三体II", "", 2},
+		{"Dune (Deluxe Edition)", "Frank Herbert", 3},
+		// 同名时作者相同的优先
+		{"dune.epub", "Someone Else", 4},
+		{"完全不同的书", "", 0},
+	}
+	for _, tt := range tests {
+		book, ok := matchBook(Clipping{Title: tt.title, Author: tt.author}, books)
+		if ok != (tt.want != 0) || ok && book.ID != tt.want {
+			t.Errorf("matchBook(%q, %q) = %d, %v, want %d", tt.title, tt.author, book.ID, ok, tt.want)
+		}
+	}
+}
+
+// 摘录与书中的标点、换行、个别字不同时仍要定位到最接近的位置
+func TestTextIndexFind(t *testing.T) {
+	lines := []string{
+		"第一章",
+		"他说：“给岁月以文明，",
+		"而不是给文明以岁月。”然后离开了。",
+		"The quick brown fox jumps over the lazy dog and keeps running far away.",
+	}
+	idx := newTextIndex(lines)
+	tests := []struct {
+		name string
+		text string
+		want [4]int // startLine, startCol, endLine, endCol
+		ok   bool
+	}{
+		{"across lines", "给岁月以文明，而不是给文明以岁月", [4]int{1, len("他说：“"), 2, len("而不是给文明以岁月")}, true},
+		{"case and punctuation", "the QUICK brown fox", [4]int{3, 0, 3, len("The quick brown fox")}, true},
+		{"changed middle", "The quick brown fox leaps over the lazy dog and keeps running far away", [4]int{3, 0, 3, len("The quick brown fox jumps over the lazy dog and keeps running far away")}, true},
+		{"missing", "完全不在书中的一句话", [4]int{}, false},
+	}
+	for _, tt := range tests {
+		sl, sc, el, ec, ok := idx.find(tt.text)
+		if ok != tt.ok || ok && [4]int{sl, sc, el, ec} != tt.want {
+			t.Errorf("%s: find = %v %v, want %v %v", tt.name, [4]int{sl, sc, el, ec}, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -22,14 +22,18 @@ func dirCmd(msg DirMsg) tea.Cmd {
 }
 
 type keyMapDir struct {
-	Back   key.Binding
-	Select key.Binding
+	Back      key.Binding
+	Select    key.Binding
+	Rule      key.Binding
+	Toggle    key.Binding
+	ToggleAll key.Binding
 }
 
 var (
 	// listTitleStyle    = lipgloss.NewStyle().MarginLeft(2)
 	_itemDirStyle      = lipgloss.NewStyle().PaddingLeft(4)
 	_selectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170"))
+	_volumeCountStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 	_keysDir           = keyMapDir{
 		Back: key.NewBinding(
 			key.WithKeys("q", "esc", "d"),
@@ -38,14 +42,31 @@ var (
 		Select: key.NewBinding(
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "Select"),
+		),
+		Rule: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "Chapter rule"),
+		),
+		Toggle: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "Fold"),
+		),
+		ToggleAll: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "Fold all"),
 		)}
 )
 
-type itemDir BookDir
+// itemDir 目录中的一项，index为其在bookDirs中的下标
+type itemDir struct {
+	index int
+}
 
-func (i itemDir) FilterValue() string { return i.name }
+func (i itemDir) FilterValue() string { return bookDirs[i.index].name }
 
-type itemDirDelegate struct{}
+type itemDirDelegate struct {
+	folded map[int]bool
+}
 
 func (d itemDirDelegate) Height() int                             { return 1 }
 func (d itemDirDelegate) Spacing() int                            { return 0 }
@@ -56,7 +77,20 @@ func (d itemDirDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 		return
 	}
 
-	str := fmt.Sprintf(" %s", i.name)
+	dir := bookDirs[i.index]
+	var str string
+	switch {
+	case dir.volume:
+		arrow := "▾"
+		if d.folded[i.index] {
+			arrow = "▸"
+		}
+		str = fmt.Sprintf("%s %s %s", arrow, dir.name, _volumeCountStyle.Render(fmt.Sprintf("(%d)", len(dir.children))))
+	case dir.parent >= 0:
+		str = fmt.Sprintf("    %s", dir.name)
+	default:
+		str = fmt.Sprintf(" %s", dir.name)
+	}
 
 	fn := _itemDirStyle.Render
 	if index == m.Index() {
@@ -71,6 +105,7 @@ func (d itemDirDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 type modelList struct {
 	list   list.Model
 	choice itemDir
+	folded map[int]bool // 折叠的卷
 }
 
 func (m modelList) Init() tea.Cmd {
@@ -87,59 +122,155 @@ func (m modelList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
 		switch {
 		case key.Matches(msg, _keysDir.Back):
+			if m.list.FilterState() == list.FilterApplied {
+				// 退出过滤，恢复折叠后的目录
+				m.list.ResetFilter()
+				return m, m.setItems(m.selected())
+			}
 			return m, viewCmd(viewMsg(viewPager))
 
+		case key.Matches(msg, _keysDir.Rule):
+			return m, tea.Batch(ruleCmd(), viewCmd(viewChapterRule))
+
+		case key.Matches(msg, _keysDir.Toggle):
+			if index := m.selected(); index >= 0 {
+				// 在章节上折叠时折叠所属的卷
+				if !bookDirs[index].volume {
+					index = bookDirs[index].parent
+				}
+				if index >= 0 {
+					m.folded[index] = !m.folded[index]
+					return m, m.setItems(index)
+				}
+			}
+			return m, nil
+
+		case key.Matches(msg, _keysDir.ToggleAll):
+			fold := false
+			for i, dir := range bookDirs {
+				if dir.volume && !m.folded[i] {
+					fold = true
+				}
+			}
+			for i, dir := range bookDirs {
+				if dir.volume {
+					m.folded[i] = fold
+				}
+			}
+			index := m.selected()
+			if fold && index >= 0 && bookDirs[index].parent >= 0 {
+				index = bookDirs[index].parent
+			}
+			return m, m.setItems(index)
+
 		case key.Matches(msg, _keysDir.Select):
 			i, ok := m.list.SelectedItem().(itemDir)
 			if ok {
 				m.choice = i
-			}
-			if m.list.Index() >= 0 {
-				title, content, index := GetBookContent(bookDirs[m.list.Index()].start)
+				title, content, index := GetBookContent(bookDirs[i.index].start)
 				return m, tea.Batch(
-					pagerCmd(pagerMsg{title: title, content: content, lastPos: GetChapterStart(m.list.Index()), currentIndex: index}),
+					pagerCmd(pagerMsg{title: title, content: content, lastPos: GetChapterStart(i.index), currentIndex: index}),
 					viewCmd(viewPager),
 				)
 			}
 			return m, nil
+
+		case msg.String() == "/" && m.list.FilterState() == list.Unfiltered:
+			// 过滤时在全部章节中查找，包括折叠起来的
+			cmds = append(cmds, m.setAllItems())
 		}
 
 	case DirMsg:
-		items := []list.Item{}
-		for _, dir := range bookDirs {
-			items = append(items, itemDir(dir))
+		// 只展开当前章节所在的卷
+		m.folded = make(map[int]bool)
+		for i, dir := range bookDirs {
+			if dir.volume {
+				m.folded[i] = true
+			}
+		}
+		if msg.index >= 0 && msg.index < len(bookDirs) {
+			if parent := bookDirs[msg.index].parent; parent >= 0 {
+				m.folded[parent] = false
+			}
 		}
-		cmds = append(cmds, m.list.SetItems(items))
-		m.list.Select(msg.index)
+		m.list.SetDelegate(itemDirDelegate{folded: m.folded})
+		m.list.ResetFilter()
+		cmds = append(cmds, m.setItems(msg.index))
 	}
 
+	filterState := m.list.FilterState()
 	m.list, cmd = m.list.Update(msg)
 	cmds = append(cmds, cmd)
+	if filterState != list.Unfiltered && m.list.FilterState() == list.Unfiltered {
+		// 取消过滤后恢复折叠后的目录
+		cmds = append(cmds, m.setItems(m.selected()))
+	}
+	if _, ok := msg.(tea.KeyMsg); ok {
+		_typing = m.list.FilterState() == list.Filtering
+	}
 	return m, tea.Batch(cmds...)
 }
 
+// selected 返回当前选中项在bookDirs中的下标
+func (m modelList) selected() int {
+	if i, ok := m.list.SelectedItem().(itemDir); ok {
+		return i.index
+	}
+	return -1
+}
+
+// setItems 按折叠状态生成目录，并选中bookDirs中下标为index的项
+func (m *modelList) setItems(index int) tea.Cmd {
+	items := []list.Item{}
+	cursor := 0
+	for i, dir := range bookDirs {
+		if dir.parent >= 0 && m.folded[dir.parent] {
+			continue
+		}
+		if i <= index {
+			cursor = len(items)
+		}
+		items = append(items, itemDir{index: i})
+	}
+	cmd := m.list.SetItems(items)
+	m.list.Select(cursor)
+	return cmd
+}
+
+func (m *modelList) setAllItems() tea.Cmd {
+	items := make([]list.Item, 0, len(bookDirs))
+	for i := range bookDirs {
+		items = append(items, itemDir{index: i})
+	}
+	return m.list.SetItems(items)
+}
+
 func (m modelList) View() string {
 	return "\n" + m.list.View()
 }
 
 func NewDirList() modelList {
 	itemDirs := []list.Item{}
-	l := list.New(itemDirs, itemDirDelegate{}, 20, 20)
+	folded := make(map[int]bool)
+	l := list.New(itemDirs, itemDirDelegate{folded: folded}, 20, 20)
 	l.Title = "Directory List"
 
 	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{_keysDir.Select}
+		return []key.Binding{_keysDir.Select, _keysDir.Toggle, _keysDir.Rule}
 	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{_keysDir.Select}
+		return []key.Binding{_keysDir.Select, _keysDir.Toggle, _keysDir.ToggleAll, _keysDir.Rule}
 	}
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = titleStyle
 
-	m := modelList{list: l}
+	m := modelList{list: l, folded: folded}
 
 	return m
 }
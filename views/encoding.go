@@ -0,0 +1,230 @@
+package views
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+const _detectSize = 16 * 1024 // 用于识别编码的字节数
+
+// _charsets chardet报告的编码名对应的解码器，按切换时的顺序排列
+// IBM420/IBM424(EBCDIC)、ISO-2022-CN/KR在x/text中没有实现
+var _charsets = []struct {
+	name string
+	enc  encoding.Encoding
+}{
+	{"UTF-8", unicode.UTF8},
+	{"UTF-16BE", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+	{"UTF-16LE", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{"UTF-32BE", utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)},
+	{"UTF-32LE", utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)},
+	{"GB-18030", simplifiedchinese.GB18030},
+	{"Big5", traditionalchinese.Big5},
+	{"Shift_JIS", japanese.ShiftJIS},
+	{"EUC-JP", japanese.EUCJP},
+	{"ISO-2022-JP", japanese.ISO2022JP},
+	{"EUC-KR", korean.EUCKR},
+	{"ISO-8859-1", charmap.ISO8859_1},
+	{"ISO-8859-2", charmap.ISO8859_2},
+	{"ISO-8859-5", charmap.ISO8859_5},
+	{"ISO-8859-6", charmap.ISO8859_6},
+	{"ISO-8859-7", charmap.ISO8859_7},
+	{"ISO-8859-8", charmap.ISO8859_8},
+	{"ISO-8859-8-I", charmap.ISO8859_8I},
+	{"ISO-8859-9", charmap.ISO8859_9},
+	{"KOI8-R", charmap.KOI8R},
+	{"windows-1250", charmap.Windows1250},
+	{"windows-1251", charmap.Windows1251},
+	{"windows-1252", charmap.Windows1252},
+	{"windows-1253", charmap.Windows1253},
+	{"windows-1254", charmap.Windows1254},
+	{"windows-1255", charmap.Windows1255},
+	{"windows-1256", charmap.Windows1256},
+}
+
+// _boms 按长度从长到短排列，UTF-32LE的BOM以UTF-16LE的BOM开头
+var _boms = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0x00, 0x00, 0xFE, 0xFF}, "UTF-32BE"},
+	{[]byte{0xFF, 0xFE, 0x00, 0x00}, "UTF-32LE"},
+	{[]byte{0xEF, 0xBB, 0xBF}, "UTF-8"},
+	{[]byte{0xFE, 0xFF}, "UTF-16BE"},
+	{[]byte{0xFF, 0xFE}, "UTF-16LE"},
+}
+
+// GetEncoding 按名称取解码器，名称不区分大小写
+func GetEncoding(charset string) (encoding.Encoding, bool) {
+	_, enc, ok := lookupCharset(charset)
+	return enc, ok
+}
+
+// lookupCharset 不区分大小写查找编码，返回_charsets中的名称，用户输入的名称先经过这里再比较或保存
+func lookupCharset(charset string) (string, encoding.Encoding, bool) {
+	for _, item := range _charsets {
+		if strings.EqualFold(item.name, charset) {
+			return item.name, item.enc, true
+		}
+	}
+	return "", nil, false
+}
+
+// detectBOM 返回BOM对应的编码和BOM长度
+func detectBOM(b []byte) (string, int) {
+	for _, item := range _boms {
+		if bytes.HasPrefix(b, item.bom) {
+			return item.charset, len(item.bom)
+		}
+	}
+	return "", 0
+}
+
+// EncodingCandidate 可选的编码，Confidence为chardet给出的可信度(0-100)
+type EncodingCandidate struct {
+	Charset    string
+	Confidence int
+	Lossless   bool // 能否无损解码文件开头
+}
+
+// encodingCandidates 列出所有可用的编码，sample为文件开头，partial表示sample不是整个文件
+// 依次为BOM、UTF-8、chardet的候选结果、其他编码，能无损解码的排在前面
+func encodingCandidates(sample []byte, partial bool) []EncodingCandidate {
+	candidates := make([]EncodingCandidate, 0, len(_charsets))
+	seen := make(map[string]bool)
+	add := func(charset string, confidence int) {
+		enc, ok := GetEncoding(charset)
+		if !ok || seen[charset] {
+			return
+		}
+		seen[charset] = true
+		candidates = append(candidates, EncodingCandidate{
+			Charset:    charset,
+			Confidence: confidence,
+			Lossless:   decodesLosslessly(enc, sample, partial),
+		})
+	}
+	if charset, _ := detectBOM(sample); charset != "" {
+		add(charset, 100)
+	}
+	// 纯ASCII也按UTF-8处理，含NUL的多半是没有BOM的UTF-16/32
+	if bytes.IndexByte(sample, 0) < 0 && validUTF8(sample, partial) {
+		add("UTF-8", 100)
+	}
+	results, _ := chardet.NewTextDetector().DetectAll(skipASCII(sample))
+	for _, result := range results {
+		add(result.Charset, result.Confidence)
+	}
+	for _, item := range _charsets {
+		add(item.name, 0)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Lossless && !candidates[j].Lossless
+	})
+	return candidates
+}
+
+// detectEncoding 取第一个识别出且能无损解码的编码
+func detectEncoding(sample []byte, partial bool) (string, error) {
+	for _, candidate := range encodingCandidates(sample, partial) {
+		if candidate.Lossless && candidate.Confidence > 0 {
+			return candidate.Charset, nil
+		}
+	}
+	return "", errors.New("Unknown encoding")
+}
+
+// skipASCII 跳过开头的纯ASCII行，避免英文的前言影响chardet的判断
+func skipASCII(sample []byte) []byte {
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return sample
+	}
+	i := bytes.IndexFunc(sample, func(r rune) bool { return r >= utf8.RuneSelf })
+	if i <= 0 {
+		return sample
+	}
+	return sample[bytes.LastIndexByte(sample[:i], '\n')+1:]
+}
+
+// readSample 读取文件开头用于识别编码
+func readSample(path string) (sample []byte, partial bool, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	sample = make([]byte, _detectSize)
+	n, err := io.ReadFull(file, sample)
+	switch err {
+	case nil:
+		partial = true
+	case io.ErrUnexpectedEOF, io.EOF:
+		err = nil
+	}
+	return sample[:n], partial, err
+}
+
+// previewLines 用charset解码sample，返回前n个非空行
+func previewLines(sample []byte, charset string, n int) []string {
+	charset, enc, ok := lookupCharset(charset)
+	if !ok {
+		return nil
+	}
+	if bomCharset, bomLen := detectBOM(sample); bomCharset == charset {
+		sample = sample[bomLen:]
+	}
+	decoded, _ := enc.NewDecoder().String(string(sample))
+	lines := make([]string, 0, n)
+	for _, line := range strings.Split(decoded, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		if len(lines) >= n {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// validUTF8 sample被截断时忽略末尾不完整的字符
+func validUTF8(b []byte, partial bool) bool {
+	if partial {
+		i := len(b) - 1
+		for i > 0 && len(b)-i < utf8.UTFMax && !utf8.RuneStart(b[i]) {
+			i--
+		}
+		if i >= 0 && !utf8.FullRune(b[i:]) {
+			b = b[:i]
+		}
+	}
+	return utf8.Valid(b)
+}
+
+// decodesLosslessly 解码结果中不含替换字符，sample被截断时忽略末尾的替换字符
+func decodesLosslessly(enc encoding.Encoding, b []byte, partial bool) bool {
+	decoded, err := enc.NewDecoder().Bytes(b)
+	if err != nil {
+		return false
+	}
+	if partial {
+		decoded = bytes.TrimRight(decoded, string(utf8.RuneError))
+	}
+	return !bytes.ContainsRune(decoded, utf8.RuneError)
+}
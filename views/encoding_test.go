@@ -0,0 +1,216 @@
+package views
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const _testChinese = "第一章 开始\n天色渐渐暗了下来，街上的行人越来越少。他站在窗前，看着远处的灯火，心里想着明天要做的事情。\n"
+
+func mustEncode(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// UTF-32LE的BOM以UTF-16LE的BOM开头，要先匹配较长的
+func TestDetectBOM(t *testing.T) {
+	tests := []struct {
+		in      []byte
+		charset string
+		n       int
+	}{
+		{[]byte{0xEF, 0xBB, 0xBF, 'a'}, "UTF-8", 3},
+		{[]byte{0xFE, 0xFF, 0, 'a'}, "UTF-16BE", 2},
+		{[]byte{0xFF, 0xFE, 'a', 0}, "UTF-16LE", 2},
+		{[]byte{0xFF, 0xFE, 0, 0}, "UTF-32LE", 4},
+		{[]byte{0, 0, 0xFE, 0xFF}, "UTF-32BE", 4},
+		{[]byte("abc"), "", 0},
+	}
+	for _, tt := range tests {
+		if charset, n := detectBOM(tt.in); charset != tt.charset || n != tt.n {
+			t.Errorf("detectBOM(% x) = %q, %d, want %q, %d", tt.in, charset, n, tt.charset, tt.n)
+		}
+	}
+}
+
+// 请求中列出的编码都要能识别
+func TestDetectEncodingCharsets(t *testing.T) {
+	tests := []struct {
+		enc  encoding.Encoding
+		text string
+		want string
+	}{
+		{simplifiedchinese.GB18030, _testChinese, "GB-18030"},
+		{traditionalchinese.Big5, "第一章 開始\n天色漸漸暗了下來，街上的行人越來越少。他站在窗前，看著遠處的燈火，心裡想著明天要做的事情。\n", "Big5"},
+		{japanese.ShiftJIS, "第一章 はじまり\n今日はとても良い天気でした。私たちは公園へ行って、長い時間を一緒に過ごしました。\n", "Shift_JIS"},
+		{japanese.EUCJP, "第一章 はじまり\n今日はとても良い天気でした。私たちは公園へ行って、長い時間を一緒に過ごしました。\n", "EUC-JP"},
+		{korean.EUCKR, "제1장 시작\n오늘은 날씨가 아주 좋았습니다. 우리는 공원에 가서 오랫동안 함께 시간을 보냈습니다.\n", "EUC-KR"},
+	}
+	for _, tt := range tests {
+		sample := mustEncode(t, tt.enc, strings.Repeat(tt.text, 3))
+		if got, err := detectEncoding(sample, false); err != nil || got != tt.want {
+			t.Errorf("detectEncoding(%s) = %q, %v, want %q", tt.want, got, err, tt.want)
+		}
+	}
+	// windows-1252和ISO-8859-1都能无损解码英文中的重音字母
+	sample := mustEncode(t, charmap.Windows1252, strings.Repeat("Café au lait, déjà vu, naïve façade. ", 5))
+	if got, err := detectEncoding(sample, false); err != nil || !strings.HasPrefix(got, "ISO-8859") && !strings.HasPrefix(got, "windows-") {
+		t.Errorf("detectEncoding(windows-1252) = %q, %v", got, err)
+	}
+}
+
+// sample在多字节字符中间截断时仍识别为UTF-8
+func TestDetectEncodingTruncatedUTF8(t *testing.T) {
+	sample := []byte(_testChinese)[:len(_testChinese)-2]
+	if got, _ := detectEncoding(sample, true); got != "UTF-8" {
+		t.Errorf("detectEncoding(partial) = %q, want UTF-8", got)
+	}
+	if validUTF8(sample, false) {
+		t.Error("validUTF8 accepted a truncated sample that is the whole file")
+	}
+}
+
+// 有BOM的文件按BOM解码，第一行不能带BOM
+func TestReadTxtBOM(t *testing.T) {
+	text := "第一行\r\n第二行\n"
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"utf8", append([]byte{0xEF, 0xBB, 0xBF}, text...)},
+		{"utf16le", mustEncode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), text)},
+		{"utf16be", mustEncode(t, unicode.UTF16(unicode.BigEndian, unicode.UseBOM), text)},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name+".txt")
+		if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		lines, _, err := readTxt(context.Background(), path, "", func(ImportProgress) {})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if want := []string{"第一行", "第二行"}; !reflect.DeepEqual(lines, want) {
+			t.Errorf("%s: readTxt = %q, want %q", tt.name, lines, want)
+		}
+	}
+}
+
+// 开头是英文前言时chardet容易认错，要跳过纯ASCII的行再识别
+func TestDetectEncodingASCIIFrontMatter(t *testing.T) {
+	text := strings.Repeat("Project Gutenberg front matter line\n", 40) + strings.Repeat(_testChinese, 3)
+	sample := mustEncode(t, simplifiedchinese.GB18030, text)
+	if got, err := detectEncoding(sample, false); err != nil || got != "GB-18030" {
+		t.Errorf("detectEncoding = %q, %v, want GB-18030", got, err)
+	}
+}
+
+// 候选编码包含所有可用的编码，各出现一次，能无损解码的在前
+func TestEncodingCandidates(t *testing.T) {
+	sample := mustEncode(t, simplifiedchinese.GB18030, strings.Repeat(_testChinese, 3))
+	candidates := encodingCandidates(sample, false)
+	if len(candidates) != len(_charsets) {
+		t.Fatalf("got %d candidates, want %d", len(candidates), len(_charsets))
+	}
+	if first := candidates[0]; first.Charset != "GB-18030" || !first.Lossless || first.Confidence == 0 {
+		t.Errorf("first candidate = %+v, want a lossless GB-18030", first)
+	}
+	seen := make(map[string]bool)
+	lossless := true
+	for _, c := range candidates {
+		if seen[c.Charset] {
+			t.Errorf("%s listed twice", c.Charset)
+		}
+		seen[c.Charset] = true
+		if c.Lossless && !lossless {
+			t.Errorf("lossless %s listed after a lossy candidate", c.Charset)
+		}
+		lossless = c.Lossless
+		if c.Charset == "UTF-8" && c.Lossless {
+			t.Error("GB-18030 text decodes losslessly as UTF-8")
+		}
+	}
+}
+
+// 用户选择的编码覆盖自动识别的结果
+func TestReadTxtCharsetOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gb.txt")
+	if err := os.WriteFile(path, mustEncode(t, simplifiedchinese.GB18030, "第一行\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lines, used, err := readTxt(context.Background(), path, "GB-18030", func(ImportProgress) {})
+	if err != nil || used != "GB-18030" || !reflect.DeepEqual(lines, []string{"第一行"}) {
+		t.Errorf("readTxt = %q, %q, %v", lines, used, err)
+	}
+	if _, _, err := readTxt(context.Background(), path, "EBCDIC", func(ImportProgress) {}); err == nil {
+		t.Error("readTxt accepted an unknown encoding")
+	}
+}
+
+func TestPreviewLines(t *testing.T) {
+	sample := append([]byte{0xEF, 0xBB, 0xBF}, "one\r\n\r\ntwo\nthree\n"...)
+	if got, want := previewLines(sample, "UTF-8", 2), []string{"one", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("previewLines = %q, want %q", got, want)
+	}
+	// 编码名不区分大小写，BOM同样要去掉
+	if got, want := previewLines(sample, "utf-8", 5), []string{"one", "two", "three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("previewLines(utf-8) = %q, want %q", got, want)
+	}
+	if got := previewLines(sample, "nope", 2); got != nil {
+		t.Errorf("previewLines(unknown) = %q, want nil", got)
+	}
+	// 换成其他编码后预览随之改变
+	gb := mustEncode(t, simplifiedchinese.GB18030, "中文\n")
+	if got := previewLines(gb, "GB-18030", 1); !reflect.DeepEqual(got, []string{"中文"}) {
+		t.Errorf("previewLines(GB-18030) = %q", got)
+	}
+	if got := previewLines(gb, "Big5", 1); reflect.DeepEqual(got, []string{"中文"}) {
+		t.Error("previewLines(Big5) decoded GB-18030 text correctly")
+	}
+}
+
+// 用户输入的编码名大小写不同时，也要去掉BOM、按UTF-8直接读取，并保存标准的名称
+func TestReadTxtCharsetCase(t *testing.T) {
+	text := "第一行\n第二行\n"
+	tests := []struct {
+		charset string
+		data    []byte
+		want    string
+	}{
+		{"utf-8", append([]byte{0xEF, 0xBB, 0xBF}, text...), "UTF-8"},
+		{"utf-16le", mustEncode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), text), "UTF-16LE"},
+		{"gb-18030", mustEncode(t, simplifiedchinese.GB18030, text), "GB-18030"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "book.txt")
+		if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		lines, used, err := readTxt(context.Background(), path, tt.charset, func(ImportProgress) {})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.charset, err)
+		}
+		if used != tt.want {
+			t.Errorf("%s: saved encoding %q, want %q", tt.charset, used, tt.want)
+		}
+		if want := []string{"第一行", "第二行"}; !reflect.DeepEqual(lines, want) {
+			t.Errorf("%s: readTxt = %q, want %q", tt.charset, lines, want)
+		}
+	}
+}
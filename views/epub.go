@@ -0,0 +1,457 @@
+package views
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-reader/dao"
+)
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Metadata epubMetadata `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// epubMetadata dc元素按本地名匹配，不区分命名空间
+type epubMetadata struct {
+	Titles       []string `xml:"title"`
+	Creators     []string `xml:"creator"`
+	Languages    []string `xml:"language"`
+	Publishers   []string `xml:"publisher"`
+	Descriptions []string `xml:"description"`
+	Subjects     []string `xml:"subject"`
+	Metas        []struct {
+		ID       string `xml:"id,attr"`
+		Name     string `xml:"name,attr"`
+		Content  string `xml:"content,attr"`
+		Property string `xml:"property,attr"`
+		Refines  string `xml:"refines,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"meta"`
+}
+
+// book 转为元数据，系列支持calibre和EPUB3的belongs-to-collection
+func (m epubMetadata) book() (book dao.Book) {
+	first := func(values []string) string {
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	book.Title = first(m.Titles)
+	authors := make([]string, 0, len(m.Creators))
+	for _, creator := range m.Creators {
+		if creator = strings.TrimSpace(creator); creator != "" {
+			authors = append(authors, creator)
+		}
+	}
+	book.Author = strings.Join(authors, ", ")
+	book.Language = first(m.Languages)
+	book.Publisher = first(m.Publishers)
+	book.Description = stripTags(first(m.Descriptions))
+	book.Tags = strings.Join(m.Subjects, ",")
+	collection := ""
+	for _, meta := range m.Metas {
+		switch {
+		case meta.Name == "calibre:series":
+			book.Series = strings.TrimSpace(meta.Content)
+		case meta.Name == "calibre:series_index":
+			book.SeriesIndex, _ = strconv.ParseFloat(strings.TrimSpace(meta.Content), 64)
+		case meta.Property == "belongs-to-collection" && book.Series == "":
+			book.Series, collection = strings.TrimSpace(meta.Value), meta.ID
+		}
+	}
+	for _, meta := range m.Metas {
+		if collection != "" && meta.Property == "group-position" && meta.Refines == "#"+collection {
+			book.SeriesIndex, _ = strconv.ParseFloat(strings.TrimSpace(meta.Value), 64)
+		}
+	}
+	return
+}
+
+// stripTags 去掉简介中的html标签
+func stripTags(s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	lines := flattenXHTML(strings.NewReader("<body>"+s+"</body>"), nil, "", map[string]int{})
+	return strings.Join(lines, "\n")
+}
+
+type ncxNavPoint struct {
+	Label    string        `xml:"navLabel>text"`
+	Content  ncxContent    `xml:"content"`
+	Children []ncxNavPoint `xml:"navPoint"`
+}
+
+type ncxContent struct {
+	Src string `xml:"src,attr"`
+}
+
+type ncxDoc struct {
+	NavPoints []ncxNavPoint `xml:"navMap>navPoint"`
+}
+
+// epubToc 目录项，href为相对压缩包根目录的路径(可带#fragment)
+type epubToc struct {
+	title string
+	href  string
+	depth int
+}
+
+// epubBook 展平后的epub，lines与txt导入后的行一一对应，dirs的start指向章节名所在行
+type epubBook struct {
+	lines []string
+	dirs  []BookDir
+	meta  dao.Book
+}
+
+// html中会断开段落的标签
+var _epubBlockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "pre": true, "hr": true, "dd": true, "dt": true,
+	"table": true, "ul": true, "ol": true, "body": true, "header": true, "footer": true,
+}
+
+// 不需要读取文字的标签
+var _epubSkipTags = map[string]bool{
+	"head": true, "script": true, "style": true, "title": true,
+}
+
+func readEpub(ctx context.Context, filepath string, progress func(ImportProgress)) (book epubBook, err error) {
+	zr, err := zip.OpenReader(filepath)
+	if err != nil {
+		return
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var container epubContainer
+	if err = readZipXML(files, "META-INF/container.xml", &container); err != nil {
+		return
+	}
+	if len(container.Rootfiles) == 0 {
+		err = errors.New("Invalid epub: no rootfile")
+		return
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var opf epubPackage
+	if err = readZipXML(files, opfPath, &opf); err != nil {
+		return
+	}
+
+	hrefs := make(map[string]string)
+	var navHref, ncxHref string
+	for _, item := range opf.Manifest {
+		href := resolveHref(opfPath, item.Href)
+		hrefs[item.ID] = href
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			navHref = href
+		}
+		if item.ID == opf.Spine.Toc || item.MediaType == "application/x-dtbncx+xml" {
+			ncxHref = href
+		}
+	}
+
+	// 按spine顺序展平所有xhtml，记录每个文件及锚点的起始行
+	all := make([]string, 0)
+	anchors := make(map[string]int)
+	total := int64(len(opf.Spine.Itemrefs))
+	for i, ref := range opf.Spine.Itemrefs {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		progress(ImportProgress{Read: int64(i), Total: total, Lines: len(all), Encoding: "EPUB"})
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		f, ok := files[href]
+		if !ok {
+			continue
+		}
+		var rc io.ReadCloser
+		rc, err = f.Open()
+		if err != nil {
+			return
+		}
+		anchors[href] = len(all)
+		all = flattenXHTML(rc, all, href, anchors)
+		rc.Close()
+	}
+	if len(all) == 0 {
+		err = errors.New("Empty epub")
+		return
+	}
+
+	var tocs []epubToc
+	if navHref != "" {
+		tocs = readNavToc(files, navHref)
+	}
+	if len(tocs) == 0 && ncxHref != "" {
+		tocs = readNcxToc(files, ncxHref)
+	}
+
+	book = buildEpubBook(all, tocs, anchors)
+	book.meta = opf.Metadata.book()
+	return
+}
+
+// buildEpubBook 将toc定位到行，章节首行不是章节名时插入章节名
+func buildEpubBook(all []string, tocs []epubToc, anchors map[string]int) epubBook {
+	type tocPos struct {
+		epubToc
+		pos int
+	}
+	positions := make([]tocPos, 0, len(tocs))
+	seen := make(map[int]bool)
+	for _, t := range tocs {
+		pos, ok := anchors[t.href]
+		if !ok {
+			// 找不到fragment时退回到文件开头
+			pos, ok = anchors[strings.SplitN(t.href, "#", 2)[0]]
+		}
+		if !ok || pos >= len(all) || seen[pos] {
+			continue
+		}
+		seen[pos] = true
+		positions = append(positions, tocPos{t, pos})
+	}
+	sort.SliceStable(positions, func(i, j int) bool { return positions[i].pos < positions[j].pos })
+
+	book := epubBook{lines: make([]string, 0, len(all)+len(positions))}
+	next := 0
+	for i, line := range all {
+		if next < len(positions) && positions[next].pos == i {
+			// 顶层且下一项为子项的作为卷
+			volume := positions[next].depth == 0 && next+1 < len(positions) && positions[next+1].depth > 0
+			title := strings.TrimSpace(positions[next].title)
+			if title == "" || title == line {
+				book.dirs = append(book.dirs, BookDir{name: line, start: len(book.lines), volume: volume})
+			} else {
+				book.dirs = append(book.dirs, BookDir{name: title, start: len(book.lines), volume: volume})
+				book.lines = append(book.lines, title)
+			}
+			next++
+		}
+		book.lines = append(book.lines, line)
+	}
+	return book
+}
+
+func flattenXHTML(r io.Reader, all []string, href string, anchors map[string]int) []string {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var sb strings.Builder
+	flush := func() {
+		line := strings.Join(strings.Fields(sb.String()), " ")
+		if line != "" {
+			all = append(all, line)
+		}
+		sb.Reset()
+	}
+	skip := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if _epubSkipTags[name] {
+				skip++
+				continue
+			}
+			if _epubBlockTags[name] {
+				flush()
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "id" {
+					anchors[href+"#"+attr.Value] = len(all)
+				}
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if _epubSkipTags[name] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if _epubBlockTags[name] {
+				flush()
+			}
+		case xml.CharData:
+			if skip == 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	flush()
+	return all
+}
+
+func readNcxToc(files map[string]*zip.File, ncxHref string) []epubToc {
+	var ncx ncxDoc
+	if err := readZipXML(files, ncxHref, &ncx); err != nil {
+		return nil
+	}
+	tocs := make([]epubToc, 0)
+	var walk func(points []ncxNavPoint, depth int)
+	walk = func(points []ncxNavPoint, depth int) {
+		for _, p := range points {
+			tocs = append(tocs, epubToc{title: p.Label, href: resolveHref(ncxHref, p.Content.Src), depth: depth})
+			walk(p.Children, depth+1)
+		}
+	}
+	walk(ncx.NavPoints, 0)
+	return tocs
+}
+
+// readNavToc 读取epub3的nav文档中 epub:type="toc" 的nav
+func readNavToc(files map[string]*zip.File, navHref string) []epubToc {
+	f, ok := files[navHref]
+	if !ok {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	tocs := make([]epubToc, 0)
+	inToc, navDepth, olDepth := false, 0, 0
+	var cur *epubToc
+	var sb strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "nav":
+				if inToc {
+					navDepth++
+					continue
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "type" && strings.Contains(attr.Value, "toc") {
+						inToc, navDepth = true, 1
+					}
+				}
+			case "ol":
+				if inToc {
+					olDepth++
+				}
+			case "a":
+				if inToc {
+					cur = &epubToc{depth: olDepth - 1}
+					for _, attr := range t.Attr {
+						if attr.Name.Local == "href" {
+							cur.href = resolveHref(navHref, attr.Value)
+						}
+					}
+					sb.Reset()
+				}
+			}
+		case xml.EndElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "nav":
+				if inToc {
+					navDepth--
+					inToc = navDepth > 0
+				}
+			case "ol":
+				if inToc {
+					olDepth--
+				}
+			case "a":
+				if cur != nil {
+					cur.title = strings.Join(strings.Fields(sb.String()), " ")
+					tocs = append(tocs, *cur)
+					cur = nil
+				}
+			}
+		case xml.CharData:
+			if cur != nil {
+				sb.Write(t)
+			}
+		}
+	}
+	return tocs
+}
+
+func readZipXML(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return errors.New("Invalid epub: missing " + name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder.Decode(v)
+}
+
+// resolveHref 将相对于base文件的href转为压缩包内路径
+func resolveHref(base string, href string) string {
+	frag := ""
+	if i := strings.Index(href, "#"); i >= 0 {
+		href, frag = href[:i], href[i:]
+	}
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	if href == "" {
+		return base + frag
+	}
+	return path.Join(path.Dir(base), href) + frag
+}
@@ -0,0 +1,149 @@
+package views
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const _testContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`
+
+// writeTestEpub 把files打包为临时目录中的epub
+func writeTestEpub(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.epub")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// epub3: 目录来自nav，有子目录的顶层条目为卷，文件名含空格
+func TestReadEpubNav(t *testing.T) {
+	path := writeTestEpub(t, map[string]string{
+		"META-INF/container.xml": _testContainer,
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="c1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c2" href="text/ch%202.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine><itemref idref="c1"/><itemref idref="c2"/></spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops"><body>
+<nav epub:type="toc"><ol>
+  <li><a href="text/ch1.xhtml">第一部</a><ol>
+    <li><a href="text/ch1.xhtml#s1">第一章 科学边界</a></li>
+  </ol></li>
+  <li><a href="text/ch%202.xhtml">第二章 射手和农场主</a></li>
+</ol></nav>
+<nav epub:type="landmarks"><ol><li><a href="text/ch1.xhtml">Start</a></li></ol></nav>
+</body></html>`,
+		"OEBPS/text/ch1.xhtml": `<html><head><title>x</title><style>p{}</style></head><body>
+<h1>第一部</h1>
+<p id="s1">正文第一段，<b>加粗</b>。</p>
+<p>第二段&nbsp;内容<br/>换行</p>
+</body></html>`,
+		"OEBPS/text/ch 2.xhtml": `<html><body><p>第二章内容</p></body></html>`,
+	})
+	book, err := readEpub(context.Background(), path, func(ImportProgress) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// head中的文字不读取，行内标签不断行，章节首行不是章节名时插入章节名
+	wantLines := []string{"第一部", "第一章 科学边界", "正文第一段，加粗。", "第二段 内容", "换行", "第二章 射手和农场主", "第二章内容"}
+	if !reflect.DeepEqual(book.lines, wantLines) {
+		t.Errorf("lines = %q, want %q", book.lines, wantLines)
+	}
+	// landmarks不是目录
+	wantDirs := []BookDir{{name: "第一部", start: 0, volume: true}, {name: "第一章 科学边界", start: 1}, {name: "第二章 射手和农场主", start: 5}}
+	if !reflect.DeepEqual(book.dirs, wantDirs) {
+		t.Errorf("dirs = %+v, want %+v", book.dirs, wantDirs)
+	}
+}
+
+// epub2: 没有nav时使用ncx，spine中找不到的条目被跳过
+func TestReadEpubNcx(t *testing.T) {
+	path := writeTestEpub(t, map[string]string{
+		"META-INF/container.xml": _testContainer,
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="c1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx"><itemref idref="c1"/><itemref idref="missing"/></spine>
+</package>`,
+		"OEBPS/toc.ncx": `<?xml version="1.0"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap>
+  <navPoint id="p1"><navLabel><text>Chapter One</text></navLabel><content src="text/ch1.xhtml"/></navPoint>
+  <navPoint id="p2"><navLabel><text>Section</text></navLabel><content src="text/ch1.xhtml#s1"/></navPoint>
+  <navPoint id="p3"><navLabel><text>Lost</text></navLabel><content src="text/gone.xhtml"/></navPoint>
+</navMap></ncx>`,
+		"OEBPS/text/ch1.xhtml": `<html><body><p>Intro line</p><p id="s1">Second line</p></body></html>`,
+	})
+	book, err := readEpub(context.Background(), path, func(ImportProgress) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLines := []string{"Chapter One", "Intro line", "Section", "Second line"}
+	if !reflect.DeepEqual(book.lines, wantLines) {
+		t.Errorf("lines = %q, want %q", book.lines, wantLines)
+	}
+	wantDirs := []BookDir{{name: "Chapter One", start: 0}, {name: "Section", start: 2}}
+	if !reflect.DeepEqual(book.dirs, wantDirs) {
+		t.Errorf("dirs = %+v, want %+v", book.dirs, wantDirs)
+	}
+}
+
+func TestReadEpubInvalid(t *testing.T) {
+	opf := `<package><manifest/><spine/></package>`
+	for name, files := range map[string]map[string]string{
+		"no container": {"OEBPS/content.opf": opf},
+		"no opf":       {"META-INF/container.xml": _testContainer},
+		"empty spine":  {"META-INF/container.xml": _testContainer, "OEBPS/content.opf": opf},
+	} {
+		if _, err := readEpub(context.Background(), writeTestEpub(t, files), func(ImportProgress) {}); err == nil {
+			t.Errorf("%s: readEpub succeeded, want an error", name)
+		}
+	}
+}
+
+func TestResolveHref(t *testing.T) {
+	tests := []struct {
+		base, href, want string
+	}{
+		{"OEBPS/content.opf", "text/ch1.xhtml", "OEBPS/text/ch1.xhtml"},
+		{"OEBPS/nav.xhtml", "text/ch%202.xhtml#s1", "OEBPS/text/ch 2.xhtml#s1"},
+		{"OEBPS/text/ch1.xhtml", "../images/a.png", "OEBPS/images/a.png"},
+		{"OEBPS/text/ch1.xhtml", "#note", "OEBPS/text/ch1.xhtml#note"},
+		{"content.opf", "ch1.xhtml", "ch1.xhtml"},
+	}
+	for _, tt := range tests {
+		if got := resolveHref(tt.base, tt.href); got != tt.want {
+			t.Errorf("resolveHref(%q, %q) = %q, want %q", tt.base, tt.href, got, tt.want)
+		}
+	}
+}
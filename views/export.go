@@ -0,0 +1,405 @@
+package views
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-reader/config"
+	"go-reader/dao"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// 导出格式
+const (
+	ExportEPUB     = "epub"
+	ExportMarkdown = "md"
+)
+
+// exportChapter 导出的一章，lines不含章节名
+type exportChapter struct {
+	title string
+	depth int // 0为卷或没有分卷时的章节，1为卷下的章节
+	lines []string
+	file  string
+}
+
+// exportBook 导出时使用的书的内容
+type exportBook struct {
+	dao.Book
+	chapters []exportChapter
+}
+
+func newExportBook(book dao.Book) (exportBook, error) {
+	lines, dirs, err := loadBook(book)
+	if err != nil {
+		return exportBook{}, err
+	}
+	chapters := make([]exportChapter, 0, len(dirs)+1)
+	// 第一章之前的内容
+	first := len(lines)
+	if len(dirs) > 0 {
+		first = dirs[0].start
+	}
+	if first > 0 && !blankLines(lines[:first]) {
+		chapters = append(chapters, exportChapter{title: book.Title, lines: lines[:first]})
+	}
+	for i, dir := range dirs {
+		end := len(lines)
+		if i+1 < len(dirs) {
+			end = dirs[i+1].start
+		}
+		depth := 0
+		if dir.parent >= 0 {
+			depth = 1
+		}
+		chapters = append(chapters, exportChapter{title: dir.name, depth: depth, lines: lines[min(dir.start+1, end):end]})
+	}
+	for i := range chapters {
+		chapters[i].file = fmt.Sprintf("chapter%04d.xhtml", i+1)
+	}
+	return exportBook{Book: book, chapters: chapters}, nil
+}
+
+// ExportPath 导出文件的默认路径
+func ExportPath(title string, format string) string {
+	return filepath.Join(config.ExportDir(), safeTitle(title)+"."+format)
+}
+
+// ExportBook 导出书，path为空时导出到ExportPath，返回导出的路径
+func ExportBook(title string, format string, path string) (string, error) {
+	book, err := dao.GetBookByName(title)
+	if err != nil {
+		return "", errors.New("no such book: " + title)
+	}
+	if format != ExportEPUB && format != ExportMarkdown {
+		return "", errors.New("unknown export format " + format)
+	}
+	if path == "" {
+		path = ExportPath(title, format)
+	}
+	exp, err := newExportBook(book)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	// 先写入临时文件，成功后改名
+	tmpPath := path + ".part"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	if format == ExportEPUB {
+		err = exp.writeEPUB(file)
+	} else {
+		err = exp.writeMarkdown(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return path, nil
+}
+
+// exportCmd 在书架中导出到ExportDir，完成后提示导出的路径
+func exportCmd(title string, format string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := ExportBook(title, format, "")
+		if err != nil {
+			return dialogMsg{Type: DialogAlert, Title: "Export Failed: " + err.Error(), Confirm: "OK"}
+		}
+		return dialogMsg{Type: DialogAlert, Title: "Exported to " + path, Confirm: "OK"}
+	}
+}
+
+func (b exportBook) language() string {
+	if b.Language != "" {
+		return b.Language
+	}
+	for _, c := range b.chapters {
+		if hasHan(c.title) || (len(c.lines) > 0 && hasHan(c.lines[0])) {
+			return "zh"
+		}
+	}
+	return "en"
+}
+
+// identifier 由书名生成固定的uuid，重复导出时保持不变
+func (b exportBook) identifier() string {
+	sum := sha1.Sum([]byte("go-reader:" + b.Title))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func (b exportBook) writeEPUB(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	// mimetype必须是第一个且不压缩
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"META-INF/container.xml", epubContainerXML},
+		{"OEBPS/content.opf", b.opf()},
+		{"OEBPS/nav.xhtml", b.nav()},
+		{"OEBPS/toc.ncx", b.ncx()},
+		{"OEBPS/style.css", epubCSS},
+	}
+	for _, c := range b.chapters {
+		files = append(files, struct {
+			name    string
+			content string
+		}{"OEBPS/" + c.file, b.chapterXHTML(c)})
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+const epubContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const epubCSS = `body { line-height: 1.6; }
+h1, h2 { text-align: center; }
+p { text-indent: 2em; margin: 0.3em 0; }
+`
+
+var esc = html.EscapeString
+
+func (b exportBook) opf() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+`)
+	fmt.Fprintf(&sb, "    <dc:identifier id=\"bookid\">%s</dc:identifier>\n", b.identifier())
+	fmt.Fprintf(&sb, "    <dc:title>%s</dc:title>\n", esc(b.Title))
+	fmt.Fprintf(&sb, "    <dc:language>%s</dc:language>\n", esc(b.language()))
+	if b.Author != "" {
+		fmt.Fprintf(&sb, "    <dc:creator>%s</dc:creator>\n", esc(b.Author))
+	}
+	if b.Publisher != "" {
+		fmt.Fprintf(&sb, "    <dc:publisher>%s</dc:publisher>\n", esc(b.Publisher))
+	}
+	if b.Description != "" {
+		fmt.Fprintf(&sb, "    <dc:description>%s</dc:description>\n", esc(b.Description))
+	}
+	for _, tag := range b.TagList() {
+		fmt.Fprintf(&sb, "    <dc:subject>%s</dc:subject>\n", esc(tag))
+	}
+	if b.Series != "" {
+		fmt.Fprintf(&sb, "    <meta property=\"belongs-to-collection\" id=\"series\">%s</meta>\n", esc(b.Series))
+		fmt.Fprintf(&sb, "    <meta refines=\"#series\" property=\"collection-type\">series</meta>\n")
+		fmt.Fprintf(&sb, "    <meta name=\"calibre:series\" content=\"%s\"/>\n", esc(b.Series))
+		if b.SeriesIndex != 0 {
+			index := strconv.FormatFloat(b.SeriesIndex, 'f', -1, 64)
+			fmt.Fprintf(&sb, "    <meta refines=\"#series\" property=\"group-position\">%s</meta>\n", index)
+			fmt.Fprintf(&sb, "    <meta name=\"calibre:series_index\" content=\"%s\"/>\n", index)
+		}
+	}
+	// 阅读进度
+	fmt.Fprintf(&sb, "    <meta name=\"go-reader:progress\" content=\"%d\"/>\n", BookProgress(b.Book))
+	fmt.Fprintf(&sb, "    <meta name=\"go-reader:position\" content=\"%d\"/>\n", b.LastPos)
+	fmt.Fprintf(&sb, "    <meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	sb.WriteString("  </metadata>\n  <manifest>\n")
+	sb.WriteString("    <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	sb.WriteString("    <item id=\"ncx\" href=\"toc.ncx\" media-type=\"application/x-dtbncx+xml\"/>\n")
+	sb.WriteString("    <item id=\"css\" href=\"style.css\" media-type=\"text/css\"/>\n")
+	for i, c := range b.chapters {
+		fmt.Fprintf(&sb, "    <item id=\"c%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, c.file)
+	}
+	sb.WriteString("  </manifest>\n  <spine toc=\"ncx\">\n")
+	for i := range b.chapters {
+		fmt.Fprintf(&sb, "    <itemref idref=\"c%d\"/>\n", i+1)
+	}
+	sb.WriteString("  </spine>\n</package>\n")
+	return sb.String()
+}
+
+// nav EPUB3的目录，卷下的章节嵌套在卷中
+func (b exportBook) nav() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s">
+<head><title>%s</title></head>
+<body>
+<nav epub:type="toc" id="toc">
+<h1>%s</h1>
+<ol>
+`, esc(b.language()), esc(b.Title), esc(b.Title))
+	for i, c := range b.chapters {
+		fmt.Fprintf(&sb, "<li><a href=\"%s\">%s</a>", c.file, esc(c.title))
+		next := 0
+		if i+1 < len(b.chapters) {
+			next = b.chapters[i+1].depth
+		}
+		switch {
+		case next > c.depth:
+			sb.WriteString("\n<ol>\n")
+		case next < c.depth:
+			// 卷结束，最后一章在卷中时也要关闭
+			sb.WriteString("</li>\n</ol>\n</li>\n")
+		default:
+			sb.WriteString("</li>\n")
+		}
+	}
+	sb.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return sb.String()
+}
+
+// ncx 供只支持EPUB2的阅读器使用
+func (b exportBook) ncx() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head><meta name="dtb:uid" content="%s"/></head>
+<docTitle><text>%s</text></docTitle>
+<navMap>
+`, b.identifier(), esc(b.Title))
+	for i, c := range b.chapters {
+		fmt.Fprintf(&sb, "<navPoint id=\"p%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/>", i+1, i+1, esc(c.title), c.file)
+		next := 0
+		if i+1 < len(b.chapters) {
+			next = b.chapters[i+1].depth
+		}
+		switch {
+		case next > c.depth:
+			sb.WriteString("\n")
+		case next < c.depth:
+			sb.WriteString("</navPoint>\n</navPoint>\n")
+		default:
+			sb.WriteString("</navPoint>\n")
+		}
+	}
+	sb.WriteString("</navMap>\n</ncx>\n")
+	return sb.String()
+}
+
+func (b exportBook) chapterXHTML(c exportChapter) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="%s">
+<head><title>%s</title><link rel="stylesheet" type="text/css" href="style.css"/></head>
+<body>
+`, esc(b.language()), esc(c.title))
+	fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", c.depth+1, esc(c.title), c.depth+1)
+	for _, line := range c.lines {
+		if line = strings.TrimSpace(line); line != "" {
+			fmt.Fprintf(&sb, "<p>%s</p>\n", esc(line))
+		}
+	}
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// writeMarkdown 书名为一级标题，卷和章节依次为二、三级标题
+func (b exportBook) writeMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	yaml := func(key string, value string) {
+		if value != "" {
+			fmt.Fprintf(bw, "%s: %s\n", key, strconv.Quote(value))
+		}
+	}
+	bw.WriteString("---\n")
+	yaml("title", b.Title)
+	yaml("author", b.Author)
+	yaml("series", FormatSeries(b.Book))
+	yaml("language", b.Language)
+	yaml("publisher", b.Publisher)
+	yaml("description", b.Description)
+	if tags := b.TagList(); len(tags) > 0 {
+		fmt.Fprintf(bw, "tags: [%s]\n", strings.Join(quoteAll(tags), ", "))
+	}
+	fmt.Fprintf(bw, "progress: %d%%\n", BookProgress(b.Book))
+	fmt.Fprintf(bw, "position: %d\n", b.LastPos)
+	bw.WriteString("---\n\n")
+	fmt.Fprintf(bw, "# %s\n", b.Title)
+	for i, c := range b.chapters {
+		// 第一章之前的内容直接放在书名下
+		if !(i == 0 && c.title == b.Title && c.depth == 0) {
+			fmt.Fprintf(bw, "\n%s %s\n", strings.Repeat("#", c.depth+2), c.title)
+		}
+		for _, line := range c.lines {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(bw, "\n%s\n", escapeMarkdown(line))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func blankLines(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return quoted
+}
+
+// escapeMarkdown 转义行首会被当作标题、列表或引用的字符
+func escapeMarkdown(line string) string {
+	if line == "" {
+		return line
+	}
+	switch line[0] {
+	case '#', '>', '-', '*', '+', '=', '|', '`':
+		return "\\" + line
+	}
+	// 1. 形式的有序列表
+	i := 0
+	for i < len(line) && line[i] >= '0' && line[i] <= '9' {
+		i++
+	}
+	if i > 0 && i < len(line) && (line[i] == '.' || line[i] == ')') {
+		return line[:i] + "\\" + line[i:]
+	}
+	return line
+}
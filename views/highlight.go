@@ -0,0 +1,252 @@
+package views
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// span 一行中[start,end)字节范围使用的样式
+type span struct {
+	start, end int
+	style      lipgloss.Style
+}
+
+var (
+	_highlightStyle = lipgloss.NewStyle().Background(lipgloss.Color("24")).Foreground(lipgloss.Color("255"))
+	_noteStyle      = _highlightStyle.Copy().Underline(true) // 带笔记的标注
+	_selectionStyle = lipgloss.NewStyle().Background(lipgloss.Color("240")).Foreground(lipgloss.Color("255"))
+	_cursorStyle    = lipgloss.NewStyle().Reverse(true)
+)
+
+// bookHighlights 当前书的所有标注，按位置排序
+var bookHighlights []dao.Highlight
+
+func loadHighlights() {
+	bookHighlights = dao.GetHighlights(bookID)
+}
+
+// decorate 按spans给text加样式，后面的span覆盖前面的，其余部分使用阅读配色
+func decorate(text string, spans []span) string {
+	if len(spans) == 0 {
+		return typo.paint(text)
+	}
+	bounds := []int{0, len(text)}
+	for _, s := range spans {
+		bounds = append(bounds, s.start, s.end)
+	}
+	sort.Ints(bounds)
+	var sb strings.Builder
+	for i := 0; i+1 < len(bounds); i++ {
+		start, end := bounds[i], bounds[i+1]
+		if start >= end {
+			continue
+		}
+		styled := false
+		for j := len(spans) - 1; j >= 0; j-- {
+			if spans[j].start <= start && end <= spans[j].end {
+				sb.WriteString(spans[j].style.Render(text[start:end]))
+				styled = true
+				break
+			}
+		}
+		if !styled {
+			sb.WriteString(typo.paint(text[start:end]))
+		}
+	}
+	return sb.String()
+}
+
+// highlightSpans 返回书中第line行[col,col+textLen)范围内的标注，偏移相对于col
+func highlightSpans(line int, col int, textLen int, srcLen int) []span {
+	spans := make([]span, 0)
+	for _, h := range bookHighlights {
+		if h.StartLine > line {
+			break
+		}
+		if h.EndLine < line {
+			continue
+		}
+		start, end := 0, srcLen
+		if h.StartLine == line {
+			start = h.StartCol
+		}
+		if h.EndLine == line {
+			end = h.EndCol
+		}
+		start, end = max(start-col, 0), min(end-col, textLen)
+		if start >= end {
+			continue
+		}
+		style := _highlightStyle
+		if h.Note != "" {
+			style = _noteStyle
+		}
+		spans = append(spans, span{start: start, end: end, style: style})
+	}
+	return spans
+}
+
+// displayPos 分页后的位置，line为pageLines的下标，off为行内字节偏移
+type displayPos struct {
+	line, off int
+}
+
+// 选择文字时的状态，分页改变后失效
+var (
+	selecting bool
+	selAnchor displayPos
+	selCursor displayPos
+)
+
+// selectable 分页后的第i行可以放置光标
+func selectable(i int) bool {
+	return i >= 0 && i < len(pageLines) && pageLines[i].src < len(procLines) && pageLines[i].text != ""
+}
+
+// startSelection 从屏幕顶部第top行开始的第一个字开始选择
+func startSelection(top int, maxHeight int) bool {
+	for i := top; i < min(top+maxHeight, len(pageLines)); i++ {
+		if selectable(i) {
+			selAnchor, selCursor = displayPos{line: i}, displayPos{line: i}
+			selecting = true
+			return true
+		}
+	}
+	return false
+}
+
+func cancelSelection() {
+	selecting = false
+}
+
+// moveCursor 左右按字移动，上下按行移动并尽量保持所在的列
+func moveCursor(dx int, dy int) {
+	cur := selCursor
+	text := pageLines[cur.line].text
+	switch {
+	case dx > 0:
+		_, size := utf8.DecodeRuneInString(text[cur.off:])
+		if cur.off+size < len(text) {
+			cur.off += size
+		} else if next := nextSelectable(cur.line, 1); next >= 0 {
+			cur = displayPos{line: next}
+		}
+	case dx < 0:
+		if cur.off > 0 {
+			_, size := utf8.DecodeLastRuneInString(text[:cur.off])
+			cur.off -= size
+		} else if prev := nextSelectable(cur.line, -1); prev >= 0 {
+			prevText := pageLines[prev].text
+			_, size := utf8.DecodeLastRuneInString(prevText)
+			cur = displayPos{line: prev, off: len(prevText) - size}
+		}
+	case dy != 0:
+		next := nextSelectable(cur.line, dy)
+		if next < 0 {
+			return
+		}
+		width := runewidth.StringWidth(text[:cur.off])
+		nextText := pageLines[next].text
+		off := len(runewidth.Truncate(nextText, width, ""))
+		if off >= len(nextText) {
+			_, size := utf8.DecodeLastRuneInString(nextText)
+			off = len(nextText) - size
+		}
+		cur = displayPos{line: next, off: off}
+	}
+	selCursor = cur
+}
+
+// nextSelectable 向dir方向找下一个可以放置光标的行，没有时返回-1
+func nextSelectable(i int, dir int) int {
+	for i += dir; i >= 0 && i < len(pageLines); i += dir {
+		if selectable(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectionBounds 选中范围的起止(都包含)
+func selectionBounds() (lo displayPos, hi displayPos) {
+	lo, hi = selAnchor, selCursor
+	if hi.line < lo.line || (hi.line == lo.line && hi.off < lo.off) {
+		lo, hi = hi, lo
+	}
+	return
+}
+
+// selectionSpans 分页后第i行中被选中的部分
+func selectionSpans(i int, text string) []span {
+	if !selecting {
+		return nil
+	}
+	spans := make([]span, 0, 2)
+	lo, hi := selectionBounds()
+	if i >= lo.line && i <= hi.line {
+		start, end := 0, len(text)
+		if i == lo.line {
+			start = lo.off
+		}
+		if i == hi.line {
+			_, size := utf8.DecodeRuneInString(text[hi.off:])
+			end = hi.off + size
+		}
+		spans = append(spans, span{start: start, end: end, style: _selectionStyle})
+	}
+	if i == selCursor.line {
+		_, size := utf8.DecodeRuneInString(text[selCursor.off:])
+		spans = append(spans, span{start: selCursor.off, end: selCursor.off + size, style: _cursorStyle})
+	}
+	return spans
+}
+
+// selectionRange 选中范围在书中的位置，end不包含
+func selectionRange() (startLine, startCol, endLine, endCol int) {
+	lo, hi := selectionBounds()
+	chapterStart := chapterContentStart()
+	startLine, startCol = chapterStart+pageLines[lo.line].src, pageLines[lo.line].col+lo.off
+	hiText := pageLines[hi.line].text
+	_, size := utf8.DecodeRuneInString(hiText[hi.off:])
+	endLine, endCol = chapterStart+pageLines[hi.line].src, pageLines[hi.line].col+hi.off+size
+	return
+}
+
+// rangeText lines中[start,end)范围的文字，跨行时以换行连接
+func rangeText(lines []string, startLine, startCol, endLine, endCol int) string {
+	if startLine < 0 || endLine >= len(lines) {
+		return ""
+	}
+	if startLine == endLine {
+		return lines[startLine][startCol:endCol]
+	}
+	parts := []string{lines[startLine][startCol:]}
+	parts = append(parts, lines[startLine+1:endLine]...)
+	parts = append(parts, lines[endLine][:endCol])
+	return strings.Join(parts, "\n")
+}
+
+// AddHighlight 保存当前选中的文字
+func AddHighlight(note string) (dao.Highlight, error) {
+	startLine, startCol, endLine, endCol := selectionRange()
+	highlight, err := dao.CreateHighlight(dao.Highlight{
+		BookID:    bookID,
+		StartLine: startLine,
+		StartCol:  startCol,
+		EndLine:   endLine,
+		EndCol:    endCol,
+		Text:      rangeText(bookAll, startLine, startCol, endLine, endCol),
+		Note:      note,
+	})
+	if err != nil {
+		return highlight, err
+	}
+	loadHighlights()
+	return highlight, nil
+}
@@ -1,16 +1,71 @@
 package views
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
 	"go-reader/utils"
 
 	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
+var (
+	_previewStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("62")).Padding(0, 1).MarginLeft(2)
+	_lossyStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+)
+
+// importProgressMsg 导入进度
+type importProgressMsg ImportProgress
+
+type importDoneMsg struct {
+	err error
+}
+
+// startImport 在后台导入，进度只保留最新的一条，避免阻塞导入
+func startImport(ctx context.Context, path string, charset string, convert string) chan tea.Msg {
+	ch := make(chan tea.Msg, 1)
+	go func() {
+		defer close(ch)
+		_, err := ImportBookContext(ctx, path, charset, convert, func(p ImportProgress) {
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- importProgressMsg(p):
+			default:
+			}
+		})
+		// 丢弃未读取的进度，确保完成消息能发出
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- importDoneMsg{err: err}
+	}()
+	return ch
+}
+
+// waitImport 等待下一条导入消息
+func waitImport(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
 type keyMapImport struct {
 	Up         key.Binding
 	Down       key.Binding
@@ -24,6 +79,11 @@ type keyMapImport struct {
 	Help       key.Binding
 	Quit       key.Binding
 	SwitchDisk map[string]key.Binding
+	Convert    key.Binding
+	// 选择编码
+	PrevEncoding key.Binding
+	NextEncoding key.Binding
+	Confirm      key.Binding
 }
 
 type modelImport struct {
@@ -31,6 +91,20 @@ type modelImport struct {
 	help         help.Model
 	filepicker   filepicker.Model
 	selectedFile string
+	width        int
+	convert      string // 导入时的简繁转换
+	// 确认编码
+	confirming bool
+	sample     []byte
+	partial    bool
+	candidates []EncodingCandidate
+	encIndex   int
+	// 导入中
+	importing bool
+	cancel    context.CancelFunc
+	ch        chan tea.Msg
+	progress  progress.Model
+	status    ImportProgress
 }
 
 // full help 行数-1
@@ -54,7 +128,7 @@ func (k keyMapImport) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Top, k.Bottom},
 		{k.Back, k.Open, k.Select, k.Quit},
-		{fullHelp, switchDisk},
+		{fullHelp, switchDisk, k.Convert},
 	}
 }
 
@@ -69,7 +143,37 @@ func (m modelImport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		winheight = msg.Height
 		m.filepicker.Height = winheight - _marginBottom
+		m.width = msg.Width
+		m.progress.Width = min(msg.Width-4, 60)
+	case importProgressMsg:
+		m.status = ImportProgress(msg)
+		return m, waitImport(m.ch)
+	case clippingsDoneMsg:
+		if msg.highlights != nil && msg.bookID == bookID {
+			bookHighlights = msg.highlights
+		}
+		return m, dialogCmd(msg.dialog)
+	case importDoneMsg:
+		m.importing, m.cancel, m.ch = false, nil, nil
+		m.selectedFile = ""
+		switch {
+		case errors.Is(msg.err, context.Canceled):
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Import cancelled", Confirm: "OK"})
+		case msg.err != nil:
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: msg.err.Error(), Confirm: "OK"})
+		}
+		return m, tea.Batch(viewCmd(viewShelf), shelfCmd(shelfMsg{msg: "refresh"}))
 	case tea.KeyMsg:
+		// 导入中只响应取消
+		if m.importing {
+			if key.Matches(msg, m.keysImport.Quit) && m.cancel != nil {
+				m.cancel()
+			}
+			return m, nil
+		}
+		if m.confirming {
+			return m.updateConfirm(msg)
+		}
 		switch {
 		case key.Matches(msg, m.keysImport.Help):
 			m.help.ShowAll = !m.help.ShowAll
@@ -87,6 +191,9 @@ func (m modelImport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, keyCmd(tea.KeyMsg{Type: tea.KeyHome})
 		case key.Matches(msg, m.keysImport.Quit):
 			return m, viewCmd(viewShelf)
+		case key.Matches(msg, m.keysImport.Convert):
+			m.convert = nextConvert(m.convert)
+			return m, nil
 		case key.Matches(msg, m.keysImport.Down):
 			msgDown := tea.KeyMsg{Type: tea.KeyDown}
 			m.filepicker, _ = m.filepicker.Update(msgDown)
@@ -117,14 +224,25 @@ func (m modelImport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Get the path of the selected file.
 		m.selectedFile = path
 		// return m, tea.Quit
-		err := ImportBook(path)
-		if err != nil {
-			// m.err = errors.New("Import failed for " + path + ".")
+		// Kindle、KOReader的标注文件导入到对应的书中
+		if IsClippingsFile(path) {
 			m.selectedFile = ""
-			return m, tea.Batch(cmd, dialogCmd(dialogMsg{Type: DialogAlert, Title: err.Error(), Confirm: "OK"}))
+			return m, tea.Batch(cmd, importClippingsCmd(path))
 		}
-
-		return m, tea.Batch(cmd, viewCmd(viewShelf), shelfCmd(shelfMsg{msg: "refresh"}))
+		// txt先确认编码
+		if _, ext, _ := PathProc(path); strings.ToLower(ext) != ".epub" {
+			sample, partial, err := readSample(path)
+			if err != nil {
+				m.selectedFile = ""
+				return m, tea.Batch(cmd, dialogCmd(dialogMsg{Type: DialogAlert, Title: err.Error(), Confirm: "OK"}))
+			}
+			m.sample, m.partial = sample, partial
+			m.candidates = encodingCandidates(sample, partial)
+			m.encIndex = 0
+			m.confirming = true
+			return m, cmd
+		}
+		return m, tea.Batch(cmd, m.startImport(""))
 	}
 
 	// Did the user select a disabled file?
@@ -137,20 +255,107 @@ func (m modelImport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// startImport 开始导入m.selectedFile
+func (m *modelImport) startImport(charset string) tea.Cmd {
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(context.Background())
+	m.ch = startImport(ctx, m.selectedFile, charset, m.convert)
+	m.importing = true
+	m.status = ImportProgress{}
+	return waitImport(m.ch)
+}
+
+func (m modelImport) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keysImport.Quit):
+		m.confirming, m.sample, m.candidates = false, nil, nil
+		m.selectedFile = ""
+	case key.Matches(msg, m.keysImport.PrevEncoding):
+		m.encIndex = (m.encIndex - 1 + len(m.candidates)) % len(m.candidates)
+	case key.Matches(msg, m.keysImport.NextEncoding):
+		m.encIndex = (m.encIndex + 1) % len(m.candidates)
+	case key.Matches(msg, m.keysImport.Convert):
+		m.convert = nextConvert(m.convert)
+	case key.Matches(msg, m.keysImport.Confirm):
+		charset := m.candidates[m.encIndex].Charset
+		m.confirming, m.sample, m.candidates = false, nil, nil
+		return m, m.startImport(charset)
+	}
+	return m, nil
+}
+
+func (m modelImport) confirmView() string {
+	candidate := m.candidates[m.encIndex]
+	s := "  " + m.selectedFile + "\n\n"
+	s += fmt.Sprintf("  Encoding: ‹ %s ›  %d%% confidence  (%d/%d)", candidate.Charset, candidate.Confidence, m.encIndex+1, len(m.candidates))
+	if !candidate.Lossless {
+		s += _lossyStyle.Render("  cannot decode losslessly")
+	}
+	s += "\n\n"
+	lines := previewLines(m.sample, candidate.Charset, max(winheight-_marginBottom-8, 3))
+	width := max(m.width-6, 10)
+	for i, line := range lines {
+		lines[i] = runewidth.Truncate(strings.ReplaceAll(line, "\t", "    "), width-2, "…")
+	}
+	s += _previewStyle.Width(width).Render(strings.Join(lines, "\n")) + "\n\n"
+	s += m.help.ShortHelpView([]key.Binding{m.keysImport.PrevEncoding, m.keysImport.NextEncoding, m.keysImport.Convert, m.keysImport.Confirm, m.keysImport.Quit})
+	return s
+}
+
 func (m modelImport) View() string {
 	s := "\n"
 	s += titleStyle.Render("Import Book")
 	s += subTitleStyle.Render(m.filepicker.CurrentDirectory)
+	if m.convert != "" {
+		s += subTitleStyle.Render(ConvertLabel(m.convert))
+	}
+	if m.importing {
+		return s + "\n\n" + m.importView()
+	}
+	if m.confirming {
+		return s + "\n\n" + m.confirmView()
+	}
 	s += "\n\n" + m.filepicker.View() + "\n"
 	helpView := m.help.View(m.keysImport)
 	s += helpView
 	return s
 }
 
+func (m modelImport) importView() string {
+	percent := 0.0
+	if m.status.Total > 0 {
+		percent = float64(m.status.Read) / float64(m.status.Total)
+	}
+	s := "  Importing " + m.selectedFile + "\n\n"
+	s += "  " + m.progress.ViewAs(percent) + "\n\n"
+	if m.status.Encoding == "EPUB" {
+		s += fmt.Sprintf("  %d/%d files, %d lines\n", m.status.Read, m.status.Total, m.status.Lines)
+	} else {
+		s += fmt.Sprintf("  %s/%s, %d lines", formatSize(m.status.Read), formatSize(m.status.Total), m.status.Lines)
+		if m.status.Encoding != "" {
+			s += ", " + m.status.Encoding
+		}
+		s += "\n"
+	}
+	s += "\n" + m.help.ShortHelpView([]key.Binding{key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "cancel"))})
+	return s
+}
+
+// formatSize 以KB/MB显示文件大小
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
 func NewImport() modelImport {
 	fp := filepicker.New()
 	fp.AutoHeight = false
-	fp.AllowedTypes = []string{".txt"}
+	fp.AllowedTypes = []string{".txt", ".epub", ".lua"}
 	fp.CurrentDirectory, _ = os.UserHomeDir()
 
 	switchDisk := make(map[string]key.Binding)
@@ -207,12 +412,29 @@ func NewImport() modelImport {
 			key.WithHelp("esc/q", "quit"),
 		),
 		SwitchDisk: switchDisk,
+		Convert: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "convert 简/繁"),
+		),
+		PrevEncoding: key.NewBinding(
+			key.WithKeys("left", "h", "shift+tab"),
+			key.WithHelp("←/h", "prev encoding"),
+		),
+		NextEncoding: key.NewBinding(
+			key.WithKeys("right", "l", "tab"),
+			key.WithHelp("→/l", "next encoding"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "import"),
+		),
 	}
 
 	m := modelImport{
 		filepicker: fp,
 		keysImport: keysImport,
 		help:       help.New(),
+		progress:   progress.New(progress.WithDefaultGradient()),
 	}
 	return m
 }
@@ -0,0 +1,422 @@
+package views
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KOReader把每本书的标注保存在书旁边的 xxx.sdr/metadata.<ext>.lua 中
+var _koreaderMetadata = regexp.MustCompile(`^metadata\..+\.lua$`)
+
+// 旧版本书签自动生成的说明，不是用户写的笔记
+var _koreaderAutoNote = regexp.MustCompile(`^(Page|页|第)\s*\S+.*@`)
+
+const _koreaderTime = "2006-01-02 15:04:05"
+
+func isKOReaderMetadata(path string) bool {
+	return _koreaderMetadata.MatchString(filepath.Base(path))
+}
+
+// parseKOReader 解析KOReader的metadata.lua，同时支持新版的annotations和旧版的highlight+bookmarks
+func parseKOReader(path string) ([]Clipping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseLua(string(data))
+	if err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	root, ok := value.(luaTable)
+	if !ok {
+		return nil, errors.New(path + ": not a KOReader metadata file")
+	}
+
+	props := root.table("doc_props")
+	title := props.str("title")
+	if title == "" {
+		title = root.table("stats").str("title")
+	}
+	if title == "" {
+		// 使用书的文件名: 书名.epub.sdr
+		title = strings.TrimSuffix(filepath.Base(filepath.Dir(path)), ".sdr")
+		title = strings.TrimSuffix(title, filepath.Ext(title))
+	}
+	author := strings.ReplaceAll(props.str("authors"), "\n", ", ")
+	if author == "" {
+		author = root.table("stats").str("authors")
+	}
+
+	clippings := make([]Clipping, 0)
+	add := func(text string, note string, datetime string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		added, _ := time.ParseInLocation(_koreaderTime, datetime, time.Local)
+		clippings = append(clippings, Clipping{
+			Title:  title,
+			Author: author,
+			Text:   text,
+			Note:   strings.TrimSpace(note),
+			Added:  added,
+			Source: SourceKOReader,
+		})
+	}
+
+	if annotations := root.table("annotations"); annotations != nil {
+		for _, a := range annotations.list() {
+			// 书签没有划线的位置
+			if a.str("pos0") == "" && a.str("drawer") == "" {
+				continue
+			}
+			add(a.str("text"), a.str("note"), a.str("datetime"))
+		}
+		return clippings, nil
+	}
+
+	// 旧版本笔记保存在对应的书签中，按时间对应
+	notes := make(map[string]string)
+	for _, b := range root.table("bookmarks").list() {
+		if b["highlighted"] != true {
+			continue
+		}
+		if note := b.str("text"); note != "" && !_koreaderAutoNote.MatchString(note) {
+			notes[b.str("datetime")] = note
+		}
+	}
+	for _, page := range root.table("highlight").list() {
+		for _, h := range page.list() {
+			add(h.str("text"), notes[h.str("datetime")], h.str("datetime"))
+		}
+	}
+	return clippings, nil
+}
+
+// luaTable 解析后的lua表，数组部分的key为"1"、"2"...
+type luaTable map[string]any
+
+func (t luaTable) str(key string) string {
+	switch v := t[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func (t luaTable) table(key string) luaTable {
+	v, _ := t[key].(luaTable)
+	return v
+}
+
+// list 按key的数字顺序返回其中的表
+func (t luaTable) list() []luaTable {
+	keys := make([]float64, 0, len(t))
+	for k := range t {
+		if n, err := strconv.ParseFloat(k, 64); err == nil {
+			keys = append(keys, n)
+		}
+	}
+	sort.Float64s(keys)
+	tables := make([]luaTable, 0, len(keys))
+	for _, k := range keys {
+		if v, ok := t[strconv.FormatFloat(k, 'f', -1, 64)].(luaTable); ok {
+			tables = append(tables, v)
+		}
+	}
+	return tables
+}
+
+// luaParser 只支持KOReader保存设置时用到的部分: return、表、字符串、数字和布尔值
+type luaParser struct {
+	s string
+	i int
+}
+
+func parseLua(s string) (any, error) {
+	p := &luaParser{s: s}
+	p.skip()
+	if strings.HasPrefix(p.s[p.i:], "return") {
+		p.i += len("return")
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if p.i < len(p.s) {
+		return nil, p.errorf("unexpected " + strconv.Quote(p.s[p.i:min(p.i+10, len(p.s))]))
+	}
+	return v, nil
+}
+
+func (p *luaParser) errorf(msg string) error {
+	line := strings.Count(p.s[:p.i], "\n") + 1
+	return errors.New("line " + strconv.Itoa(line) + ": " + msg)
+}
+
+// skip 跳过空白和注释
+func (p *luaParser) skip() {
+	for p.i < len(p.s) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(p.s[p.i])):
+			p.i++
+		case strings.HasPrefix(p.s[p.i:], "--[["):
+			end := strings.Index(p.s[p.i:], "]]")
+			if end < 0 {
+				p.i = len(p.s)
+			} else {
+				p.i += end + 2
+			}
+		case strings.HasPrefix(p.s[p.i:], "--"):
+			end := strings.IndexByte(p.s[p.i:], '\n')
+			if end < 0 {
+				p.i = len(p.s)
+			} else {
+				p.i += end + 1
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *luaParser) value() (any, error) {
+	p.skip()
+	if p.i >= len(p.s) {
+		return nil, p.errorf("unexpected end of file")
+	}
+	switch c := p.s[p.i]; {
+	case c == '{':
+		return p.table()
+	case c == '"' || c == '\'':
+		return p.str()
+	case strings.HasPrefix(p.s[p.i:], "[["), strings.HasPrefix(p.s[p.i:], "[="):
+		return p.longStr()
+	}
+	word := p.word()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "nil":
+		return nil, nil
+	case "":
+		return nil, p.errorf("unexpected " + strconv.Quote(p.s[p.i:p.i+1]))
+	}
+	n, err := strconv.ParseFloat(word, 64)
+	if err != nil {
+		if i, err := strconv.ParseInt(word, 0, 64); err == nil {
+			return float64(i), nil
+		}
+		return nil, p.errorf("invalid value " + word)
+	}
+	return n, nil
+}
+
+// word 标识符或数字
+func (p *luaParser) word() string {
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+			(c == '-' || c == '+') && (p.i == start || p.s[p.i-1] == 'e' || p.s[p.i-1] == 'E') {
+			p.i++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.i]
+}
+
+func (p *luaParser) table() (luaTable, error) {
+	p.i++ // {
+	t := make(luaTable)
+	n := 0
+	for {
+		p.skip()
+		if p.i >= len(p.s) {
+			return nil, p.errorf("unclosed table")
+		}
+		if p.s[p.i] == '}' {
+			p.i++
+			return t, nil
+		}
+		var key string
+		switch {
+		case p.s[p.i] == '[' && !strings.HasPrefix(p.s[p.i:], "[[") && !strings.HasPrefix(p.s[p.i:], "[="):
+			p.i++
+			k, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			switch k := k.(type) {
+			case string:
+				key = k
+			case float64:
+				key = strconv.FormatFloat(k, 'f', -1, 64)
+			case bool:
+				key = strconv.FormatBool(k)
+			default:
+				return nil, p.errorf("invalid table key")
+			}
+			p.skip()
+			if !strings.HasPrefix(p.s[p.i:], "]") {
+				return nil, p.errorf("expected ]")
+			}
+			p.i++
+			if err := p.expect('='); err != nil {
+				return nil, err
+			}
+		default:
+			// name = value 或数组元素
+			start := p.i
+			word := p.word()
+			p.skip()
+			if word != "" && p.i < len(p.s) && p.s[p.i] == '=' && !strings.HasPrefix(p.s[p.i:], "==") {
+				p.i++
+				key = word
+			} else {
+				p.i = start
+				n++
+				key = strconv.Itoa(n)
+			}
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			t[key] = v
+		}
+		p.skip()
+		if p.i < len(p.s) && (p.s[p.i] == ',' || p.s[p.i] == ';') {
+			p.i++
+		}
+	}
+}
+
+func (p *luaParser) expect(c byte) error {
+	p.skip()
+	if p.i >= len(p.s) || p.s[p.i] != c {
+		return p.errorf("expected " + string(c))
+	}
+	p.i++
+	return nil
+}
+
+func (p *luaParser) str() (string, error) {
+	quote := p.s[p.i]
+	p.i++
+	var sb strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == quote:
+			p.i++
+			return sb.String(), nil
+		case c == '\n':
+			return "", p.errorf("unfinished string")
+		case c != '\\':
+			sb.WriteByte(c)
+			p.i++
+			continue
+		}
+		// 转义
+		p.i++
+		if p.i >= len(p.s) {
+			break
+		}
+		c = p.s[p.i]
+		p.i++
+		switch c {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'v':
+			sb.WriteByte('\v')
+		case '\n':
+			sb.WriteByte('\n')
+		case 'z':
+			for p.i < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.i])) {
+				p.i++
+			}
+		case 'x':
+			if p.i+2 > len(p.s) {
+				return "", p.errorf("invalid escape")
+			}
+			b, err := strconv.ParseUint(p.s[p.i:p.i+2], 16, 8)
+			if err != nil {
+				return "", p.errorf("invalid escape")
+			}
+			sb.WriteByte(byte(b))
+			p.i += 2
+		case 'u':
+			end := strings.IndexByte(p.s[p.i:], '}')
+			if !strings.HasPrefix(p.s[p.i:], "{") || end < 0 {
+				return "", p.errorf("invalid escape")
+			}
+			r, err := strconv.ParseUint(p.s[p.i+1:p.i+end], 16, 32)
+			if err != nil {
+				return "", p.errorf("invalid escape")
+			}
+			sb.WriteRune(rune(r))
+			p.i += end + 1
+		default:
+			if c >= '0' && c <= '9' {
+				// \ddd 十进制字节
+				end := p.i - 1
+				for end < len(p.s) && end < p.i+2 && p.s[end] >= '0' && p.s[end] <= '9' {
+					end++
+				}
+				b, err := strconv.ParseUint(p.s[p.i-1:end], 10, 8)
+				if err != nil {
+					return "", p.errorf("invalid escape")
+				}
+				sb.WriteByte(byte(b))
+				p.i = end
+			} else {
+				sb.WriteByte(c)
+			}
+		}
+	}
+	return "", p.errorf("unfinished string")
+}
+
+// longStr [[...]] 或 [==[...]==]
+func (p *luaParser) longStr() (string, error) {
+	level := 0
+	for p.i+1+level < len(p.s) && p.s[p.i+1+level] == '=' {
+		level++
+	}
+	if p.i+1+level >= len(p.s) || p.s[p.i+1+level] != '[' {
+		return "", p.errorf("invalid long string")
+	}
+	p.i += level + 2
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(p.s[p.i:], closing)
+	if end < 0 {
+		return "", p.errorf("unfinished long string")
+	}
+	s := p.s[p.i : p.i+end]
+	p.i += end + len(closing)
+	// 开头的换行被忽略
+	return strings.TrimPrefix(strings.TrimPrefix(s, "\r"), "\n"), nil
+}
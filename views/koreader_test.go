@@ -0,0 +1,183 @@
+package views
+
+import (
+	"reflect"
+	"testing"
+)
+
+// KOReader用dump()保存的设置文件，只用到lua语法的一部分
+func TestParseLua(t *testing.T) {
+	got, err := parseLua(`-- we can read Lua syntax here!
+return {
+    ["doc_props"] = {
+        ["title"] = "T",
+        authors = 'it\'s "me"',
+    }, --[[ block
+    comment ]]
+    [1] = { [2] = "b" };
+    ["numbers"] = {1, -2.5, 0x10, 1e3},
+    ["flags"] = {true, false, nil, skipped = nil},
+    ["escapes"] = "a\"b\n\65\x42\u{4E2D}\z
+                   c",
+    ["long"] = [==[
+line]]
+]==],
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := luaTable{
+		"doc_props": luaTable{"title": "T", "authors": `it's "me"`},
+		"1":         luaTable{"2": "b"},
+		"numbers":   luaTable{"1": 1.0, "2": -2.5, "3": 16.0, "4": 1000.0},
+		"flags":     luaTable{"1": true, "2": false},
+		"escapes":   "a\"b\nAB中c",
+		"long":      "line]]\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLua = %#v\nwant %#v", got, want)
+	}
+}
+
+// 出错时报告行号，方便用户找到损坏的文件
+func TestParseLuaErrors(t *testing.T) {
+	for in, want := range map[string]string{
+		"return {":                 "line 1: unclosed table",
+		"return {\n a = \"x\n}":    "line 2: unfinished string",
+		"return [[abc":             "line 1: unfinished long string",
+		"return {\n\n a = bad }":   "line 3: invalid value bad",
+		"return {} {}":             "line 1: unexpected \"{}\"",
+		"return":                   "line 1: unexpected end of file",
+		"return {\n [{}] = 1 }":    "line 2: invalid table key",
+		"return { a = \"\\xZZ\" }": "line 1: invalid escape",
+	} {
+		if _, err := parseLua(in); err == nil || err.Error() != want {
+			t.Errorf("parseLua(%q) error = %v, want %q", in, err, want)
+		}
+	}
+}
+
+// 新版本的annotations，页码书签没有划线的位置，不是标注
+func TestParseKOReaderAnnotations(t *testing.T) {
+	path := writeTestFile(t, "new.epub.sdr/metadata.epub.lua", `return {
+    ["annotations"] = {
+        [1] = {
+            ["datetime"] = "2024-01-02 10:20:30",
+            ["drawer"] = "lighten",
+            ["note"] = "  想法  ",
+            ["pos0"] = "/body/DocFragment[2]/body/p[1]/text().0",
+            ["text"] = "第一段",
+        },
+        [2] = {
+            ["datetime"] = "2024-01-02 10:21:00",
+            ["page"] = 12,
+            ["text"] = "Page 12 bookmark",
+        },
+        [10] = {
+            ["datetime"] = "2024-01-03 08:00:00",
+            ["drawer"] = "underscore",
+            ["text"] = "第十条",
+        },
+    },
+    ["doc_props"] = {
+        ["authors"] = "甲\n乙",
+        ["title"] = "新书",
+    },
+}`)
+	clippings, err := parseKOReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clippings) != 2 {
+		t.Fatalf("got %d clippings, want 2: %+v", len(clippings), clippings)
+	}
+	// 按数字顺序而不是字符串顺序，[10]在[1]之后
+	first, second := clippings[0], clippings[1]
+	if first.Text != "第一段" || first.Note != "想法" || first.Title != "新书" || first.Author != "甲, 乙" ||
+		first.Source != SourceKOReader || first.Added.Format(_koreaderTime) != "2024-01-02 10:20:30" {
+		t.Errorf("first clipping = %+v", first)
+	}
+	if second.Text != "第十条" || second.Note != "" {
+		t.Errorf("second clipping = %+v", second)
+	}
+}
+
+// 旧版本的标注在highlight中，笔记保存在datetime相同的书签里
+func TestParseKOReaderLegacyNotes(t *testing.T) {
+	path := writeTestFile(t, "Old Book.epub.sdr/metadata.epub.lua", `return {
+    ["bookmarks"] = {
+        [1] = {
+            ["datetime"] = "2020-05-06 07:08:09",
+            ["highlighted"] = true,
+            ["notes"] = "old text",
+            ["text"] = "旧笔记",
+        },
+        [2] = {
+            ["datetime"] = "2020-05-06 07:09:00",
+            ["highlighted"] = true,
+            ["text"] = "Page 3 another @ 2020-05-06 07:09:00",
+        },
+        [3] = {
+            ["datetime"] = "2020-05-06 07:10:00",
+            ["text"] = "not highlighted",
+        },
+    },
+    ["highlight"] = {
+        [3] = {
+            [1] = {
+                ["datetime"] = "2020-05-06 07:08:09",
+                ["text"] = "old text",
+            },
+            [2] = {
+                ["datetime"] = "2020-05-06 07:09:00",
+                ["text"] = "another",
+            },
+        },
+    },
+    ["stats"] = {
+        ["authors"] = "Author",
+    },
+}`)
+	clippings, err := parseKOReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clippings) != 2 {
+		t.Fatalf("got %d clippings, want 2: %+v", len(clippings), clippings)
+	}
+	if c := clippings[0]; c.Text != "old text" || c.Note != "旧笔记" {
+		t.Errorf("first clipping = %+v, want the note from its bookmark", c)
+	}
+	// 自动生成的"Page 3 ... @ 时间"不是笔记
+	if c := clippings[1]; c.Text != "another" || c.Note != "" {
+		t.Errorf("second clipping = %+v, want no note", c)
+	}
+	// 没有doc_props时书名来自xxx.epub.sdr目录名
+	if c := clippings[0]; c.Title != "Old Book" || c.Author != "Author" {
+		t.Errorf("title, author = %q, %q, want Old Book, Author", c.Title, c.Author)
+	}
+}
+
+func TestParseKOReaderNotMetadata(t *testing.T) {
+	path := writeTestFile(t, "x.sdr/metadata.epub.lua", `return "text"`)
+	if _, err := parseKOReader(path); err == nil {
+		t.Error("parseKOReader accepted a file that is not a table")
+	}
+}
+
+func TestIsClippingsFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"book.sdr/metadata.epub.lua":        true,
+		"metadata.pdf.lua":                  true,
+		"metadata.lua":                      false,
+		"book.sdr/metadata.epub.lua.old":    false,
+		"Kindle/documents/My Clippings.txt": true,
+		"my clippings.TXT":                  true,
+		"clippings.txt":                     false,
+	} {
+		if got := IsClippingsFile(path); got != want {
+			t.Errorf("IsClippingsFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package views
+
+import (
+	"fmt"
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const _maxLibSearchHits = 500 // 每本书最多保留的结果数
+
+// libHit 书库搜索的结果
+type libHit struct {
+	book    string
+	chapter string
+	searchHit
+	snippet string
+}
+
+// libSearchMsg 一本书的搜索结果，gen用于丢弃已取消的搜索
+type libSearchMsg struct {
+	gen  int
+	book string
+	hits []libHit
+}
+
+type libSearchDoneMsg struct {
+	gen int
+}
+
+var _libSearchGen = 0
+
+// startLibSearch 在后台逐本搜索，每搜完一本通过channel发送一次结果
+func startLibSearch(query string, useRegex bool, done <-chan struct{}) (chan tea.Msg, error) {
+	re, err := compileSearch(query, useRegex)
+	if err != nil {
+		return nil, err
+	}
+	_libSearchGen++
+	gen := _libSearchGen
+	ch := make(chan tea.Msg)
+	go func() {
+		defer close(ch)
+		for _, book := range dao.GetBooks() {
+			lines, dirs, err := loadBook(book)
+			if err != nil {
+				continue
+			}
+			found := findHits(lines, re, _maxLibSearchHits)
+			if len(found) == 0 {
+				continue
+			}
+			hits := make([]libHit, 0, len(found))
+			for _, hit := range found {
+				chapter := chapterPath(dirs, chapterIndex(dirs, hit.line))
+				hits = append(hits, libHit{
+					book:      book.Title,
+					chapter:   chapter,
+					searchHit: hit,
+					snippet:   hitSnippet(lines[hit.line], hit.start, hit.end),
+				})
+			}
+			select {
+			case ch <- libSearchMsg{gen: gen, book: book.Title, hits: hits}:
+			case <-done:
+				return
+			}
+		}
+		select {
+		case ch <- libSearchDoneMsg{gen: gen}:
+		case <-done:
+		}
+	}()
+	return ch, nil
+}
+
+// waitLibSearch 等待下一本书的结果
+func waitLibSearch(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+type keyMapLibSearch struct {
+	Back        key.Binding
+	Cancel      key.Binding
+	Select      key.Binding
+	Search      key.Binding
+	ToggleRegex key.Binding
+}
+
+var _keysLibSearch = keyMapLibSearch{
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("q", "back"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/", "s"),
+		key.WithHelp("/", "new search"),
+	),
+	ToggleRegex: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+	),
+}
+
+type itemLibHit libHit
+
+func (i itemLibHit) Title() string {
+	if i.chapter == "" {
+		return "  " + i.book
+	}
+	return "  " + i.chapter
+}
+func (i itemLibHit) Description() string { return "  " + i.snippet }
+func (i itemLibHit) FilterValue() string { return i.book + i.chapter + i.snippet }
+
+// itemLibBook 一本书的分组标题
+type itemLibBook struct {
+	book  string
+	first libHit
+	count int
+}
+
+func (i itemLibBook) Title() string { return i.book }
+func (i itemLibBook) Description() string {
+	if i.count >= _maxLibSearchHits {
+		return fmt.Sprintf("%d+ hits", i.count)
+	}
+	return fmt.Sprintf("%d hits", i.count)
+}
+func (i itemLibBook) FilterValue() string { return i.book }
+
+type modelLibSearch struct {
+	list      list.Model
+	input     textinput.Model
+	useRegex  bool
+	query     string
+	gen       int
+	searching bool
+	ch        chan tea.Msg
+	done      chan struct{}
+}
+
+// libSearchFocusMsg 进入书库搜索时聚焦输入框
+type libSearchFocusMsg struct{}
+
+func libSearchCmd() tea.Cmd {
+	return func() tea.Msg {
+		return libSearchFocusMsg{}
+	}
+}
+
+func (m modelLibSearch) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelLibSearch) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := _docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v-2)
+		return m, nil
+	case libSearchFocusMsg:
+		m.input.SetValue(m.query)
+		m.input.CursorEnd()
+		_typing = true
+		return m, m.input.Focus()
+	case tea.KeyMsg:
+		if m.input.Focused() {
+			switch {
+			case key.Matches(msg, _keysLibSearch.Cancel):
+				m.input.Blur()
+				_typing = false
+				if m.query == "" {
+					return m, viewCmd(viewShelf)
+				}
+				return m, nil
+			case key.Matches(msg, _keysLibSearch.ToggleRegex):
+				m.useRegex = !m.useRegex
+				m.input.Prompt = searchPrompt(m.useRegex)
+				return m, nil
+			case key.Matches(msg, _keysLibSearch.Select):
+				if m.input.Value() == "" {
+					return m, nil
+				}
+				m.input.Blur()
+				_typing = false
+				m.stop()
+				m.done = make(chan struct{})
+				ch, err := startLibSearch(m.input.Value(), m.useRegex, m.done)
+				if err != nil {
+					return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: err.Error(), Confirm: "OK"})
+				}
+				m.ch, m.query, m.gen, m.searching = ch, m.input.Value(), _libSearchGen, true
+				m.list.Title = "Library Search: " + m.query
+				return m, tea.Batch(m.list.SetItems([]list.Item{}), waitLibSearch(ch))
+			}
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		switch {
+		case key.Matches(msg, _keysLibSearch.Back):
+			m.stop()
+			return m, viewCmd(viewShelf)
+		case key.Matches(msg, _keysLibSearch.Search):
+			_typing = true
+			return m, m.input.Focus()
+		case key.Matches(msg, _keysLibSearch.Select):
+			var hit libHit
+			switch item := m.list.SelectedItem().(type) {
+			case itemLibHit:
+				hit = libHit(item)
+			case itemLibBook:
+				hit = item.first
+			default:
+				return m, nil
+			}
+			m.stop()
+			cmd = openBook(hit.book, hit.line, hit.start)
+			// 打开后在该书中继续搜索，可用n/N跳转
+			if err := SearchBook(m.query, m.useRegex); err == nil {
+				searchIndex = nextHit(hit.line, hit.start-1, true)
+			}
+			return m, cmd
+		}
+	case libSearchMsg:
+		if msg.gen != m.gen {
+			return m, nil
+		}
+		items := m.list.Items()
+		items = append(items, itemLibBook{book: msg.book, first: msg.hits[0], count: len(msg.hits)})
+		for _, hit := range msg.hits {
+			items = append(items, itemLibHit(hit))
+		}
+		return m, tea.Batch(m.list.SetItems(items), waitLibSearch(m.ch))
+	case libSearchDoneMsg:
+		if msg.gen == m.gen {
+			m.searching = false
+		}
+		return m, nil
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// stop 取消正在进行的搜索
+func (m *modelLibSearch) stop() {
+	if m.done != nil {
+		close(m.done)
+		m.done = nil
+	}
+	m.searching = false
+}
+
+func (m modelLibSearch) View() string {
+	status := ""
+	if m.searching {
+		status = subTitleStyle.Render("searching...")
+	}
+	return _docStyle.Render(m.list.View() + "\n" + m.input.View() + status)
+}
+
+func NewLibSearch() modelLibSearch {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Library Search"
+	l.Styles.Title = titleStyle
+	l.SetFilteringEnabled(false)
+	l.SetStatusBarItemName("result", "results")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysLibSearch.Select, _keysLibSearch.Search}
+	}
+
+	input := textinput.New()
+	input.Prompt = searchPrompt(false)
+	input.Placeholder = "search all books (ctrl+r: regex)"
+
+	return modelLibSearch{list: l, input: input}
+}
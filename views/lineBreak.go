@@ -0,0 +1,133 @@
+package views
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// 避头尾规则(kinsoku)
+const (
+	// _noStart 不能出现在行首的字符
+	_noStart = ",.;:!?)]}%" +
+		"，。、．：；！？）】」』〕〉》〗〙〛｝］’”…‥・ー々〆ゝゞヽヾ～％‰℃" +
+		"ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ"
+	// _noEnd 不能出现在行尾的字符
+	_noEnd = "([{" + "（【「『〔〈《〖〘〚｛［‘“￥＄"
+	// _hangable 可以悬挂在行尾边距中的标点
+	_hangable = "，。、．,."
+	// _inseparable 连续出现时不能分开的字符
+	_inseparable = "…‥—"
+)
+
+const _minHyphenPart = 3 // 连字符两边至少保留的字母数
+
+// breakLine 返回line中放在宽度为width的一行中的字节数。
+// hang为行尾可以悬挂标点的宽度，hyphen为true时在行尾显示连字符
+func breakLine(line string, width int, hang int, hyphenate bool) (n int, hyphen bool) {
+	runes := []rune(line)
+	fit, w := 0, 0
+	for fit < len(runes) {
+		rw := runewidth.RuneWidth(runes[fit])
+		if w+rw > width {
+			break
+		}
+		w += rw
+		fit++
+	}
+	if fit == len(runes) {
+		return len(line), false
+	}
+	// 宽度不足一个字符时至少放一个字符
+	fit = max(fit, 1)
+	if fit >= len(runes) {
+		return len(line), false
+	}
+
+	// 行首禁则的标点可以悬挂时放在本行末尾
+	if r := runes[fit]; strings.ContainsRune(_hangable, r) && runewidth.RuneWidth(r) <= hang &&
+		(fit+1 == len(runes) || canBreak(r, runes[fit+1])) {
+		return runesLen(runes[:fit+1]), false
+	}
+
+	best := 0
+	for i := fit; i > 0; i-- {
+		if canBreak(runes[i-1], runes[i]) {
+			best = i
+			break
+		}
+	}
+	if hyphenate {
+		if i := hyphenPoint(runes, fit); i > best {
+			return runesLen(runes[:i]), true
+		}
+	}
+	if best == 0 {
+		// 找不到可以断行的位置时按宽度截断
+		return runesLen(runes[:fit]), false
+	}
+	return runesLen(runes[:best]), false
+}
+
+// canBreak 字符a和b之间是否可以换行
+func canBreak(a rune, b rune) bool {
+	switch {
+	case a == b && strings.ContainsRune(_inseparable, a):
+		return false
+	case strings.ContainsRune(_noStart, b), strings.ContainsRune(_noEnd, a):
+		return false
+	case unicode.IsSpace(a), unicode.IsSpace(b):
+		return true
+	case runewidth.RuneWidth(a) == 2, runewidth.RuneWidth(b) == 2:
+		// 中日韩文字之间以及和西文之间
+		return true
+	case a == '-', a == '/':
+		return isLatinLetter(b)
+	}
+	// 西文单词中间
+	return false
+}
+
+// hyphenPoint 在跨越第fit个字符的单词中找加连字符断开的位置，连字符也要放在本行，没有时返回0
+func hyphenPoint(runes []rune, fit int) int {
+	if !isLatinLetter(runes[fit-1]) || !isLatinLetter(runes[fit]) {
+		return 0
+	}
+	start, end := fit, fit
+	for start > 0 && isLatinLetter(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isLatinLetter(runes[end]) {
+		end++
+	}
+	k := min(fit-1, end-_minHyphenPart)
+	if k < start+_minHyphenPart {
+		return 0
+	}
+	// 优先在元音和辅音之间或两个相同的辅音之间断开
+	for i := k; i >= max(start+_minHyphenPart, k-2); i-- {
+		a, b := unicode.ToLower(runes[i-1]), unicode.ToLower(runes[i])
+		if isVowel(a) && !isVowel(b) || a == b && !isVowel(a) {
+			return i
+		}
+	}
+	return k
+}
+
+func isLatinLetter(r rune) bool {
+	return unicode.In(r, unicode.Latin) && runewidth.RuneWidth(r) == 1
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouy", r)
+}
+
+func runesLen(runes []rune) int {
+	n := 0
+	for _, r := range runes {
+		n += utf8.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestBreakLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		width     int
+		hang      int
+		hyphenate bool
+		want      string // 本行的内容
+		hyphen    bool
+	}{
+		{"fits", "你好世界", 8, 0, false, "你好世界", false},
+		{"cjk", "你好世界", 6, 0, false, "你好世", false},
+		// 句号不能在行首，前一个字一起换到下一行
+		{"no closing at start", "你好世。界", 6, 0, false, "你好", false},
+		{"closing quote", "他说「好」。", 8, 0, false, "他说", false},
+		// 右边距放得下时句号悬挂在行尾
+		{"hang", "你好世。界", 6, 2, false, "你好世。", false},
+		{"hang too narrow", "你好世，界", 6, 1, false, "你好", false},
+		{"no opening at end", "你好「世界」", 6, 0, false, "你好", false},
+		{"ellipsis", "好好……啊", 4, 0, false, "好", false},
+		{"latin word", "hello world again", 10, 0, false, "hello ", false},
+		{"latin and cjk", "中文abc中文", 7, 0, false, "中文abc", false},
+		{"after dash", "well-known fact", 8, 0, false, "well-", false},
+		// 没有可以断开的位置时按宽度截断
+		{"long word", "abcdefghij", 4, 0, false, "abcd", false},
+		// 终端太窄或缩进比正文宽时，宽度不足一个字符也至少放一个字符
+		{"single wide rune", "你", 1, 0, false, "你", false},
+		{"zero width", "你", 0, 2, false, "你", false},
+		{"negative width", "a", -3, 0, true, "a", false},
+		{"narrow cjk", "你好", 1, 0, false, "你", false},
+		{"narrow hangs punct", "你。", 1, 2, false, "你。", false},
+		{"narrow latin", "ab", 0, 0, true, "a", false},
+		{"hyphenate", "an extraordinary day", 13, 0, true, "an extraordi", true},
+		{"hyphenate short word", "a cat sat", 4, 0, true, "a ", false},
+		{"hyphenate off", "an extraordinary day", 13, 0, false, "an ", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, hyphen := breakLine(tt.line, tt.width, tt.hang, tt.hyphenate)
+			if got := tt.line[:n]; got != tt.want || hyphen != tt.hyphen {
+				t.Errorf("breakLine(%q, %d) = %q, %v, want %q, %v", tt.line, tt.width, got, hyphen, tt.want, tt.hyphen)
+			}
+		})
+	}
+}
+
+// 整段断行后每行都不以禁止行首的字符开头、不以禁止行尾的字符结尾
+// 宽度小于6时"好，”"这样的组合放不下，只能被迫截断
+func TestBreakLineKinsoku(t *testing.T) {
+	text := "“你好，”他说。「今天（星期三）的天气真好！」她笑了笑……然后走了——没有回头。"
+	for width := 6; width <= 20; width++ {
+		rest := text
+		for rest != "" {
+			n, _ := breakLine(rest, width, 0, false)
+			line := rest[:n]
+			if w := runewidth.StringWidth(line); w > width {
+				t.Fatalf("width %d: line %q is %d wide", width, line, w)
+			}
+			rest = rest[n:]
+			if rest == "" {
+				break
+			}
+			first, _ := utf8.DecodeRuneInString(rest)
+			last, _ := utf8.DecodeLastRuneInString(line)
+			if strings.ContainsRune(_noStart, first) || strings.ContainsRune(_noEnd, last) {
+				t.Errorf("width %d: bad break %q | %q", width, line, rest)
+			}
+		}
+	}
+}
+
+// 首行缩进比正文还宽时不能越界，每个字都要保留
+func TestWrapLinesIndentWiderThanText(t *testing.T) {
+	saved := typo
+	t.Cleanup(func() { typo = saved })
+	typo.indent, typo.margin, typo.maxWidth = true, 10, 0
+
+	lines := []string{"中文段落。", "ab"}
+	for width := 0; width <= 8; width++ {
+		wrapped := wrapLines(lines, width)
+		var got strings.Builder
+		for _, l := range wrapped {
+			if l.src == 0 {
+				got.WriteString(l.text)
+			}
+		}
+		if got.String() != lines[0] {
+			t.Errorf("width %d: wrapped text = %q, want %q", width, got.String(), lines[0])
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package views
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const _headerLines = 30 // 在txt开头多少行内查找元数据
+
+var (
+	// 《书名》作者：xxx、书名 作者：xxx
+	_fileNameBracket = regexp.MustCompile(`^《(.+?)》\s*(?:作者[:：]?)?\s*(.*)$`)
+	_fileNameAuthor  = regexp.MustCompile(`^(.+?)\s*作者[:：]\s*(.+)$`)
+	_fileNameBy      = regexp.MustCompile(`(?i)^(.+?)\s+by\s+(.+)$`)
+	// 书名 作者、书名-作者、书名_作者，两边都含汉字时才拆分
+	_fileNameSep = regexp.MustCompile(`^(\S+)\s*[ _\-—]\s*(\S+)$`)
+	// 书名：xxx
+	_headerField = regexp.MustCompile(`^\s*[【\[]?([^:：【】\[\]]{1,8})[】\]]?\s*[:：]\s*(.+?)\s*$`)
+)
+
+// _headerKeys txt开头的字段名
+var _headerKeys = map[string]string{
+	"书名": "title", "作品名称": "title", "title": "title",
+	"作者": "author", "author": "author",
+	"系列": "series", "series": "series",
+	"语言": "language", "language": "language",
+	"出版社": "publisher", "publisher": "publisher",
+	"简介": "description", "内容简介": "description", "内容介绍": "description", "description": "description",
+	"标签": "tags", "tags": "tags", "类型": "tags", "分类": "tags",
+}
+
+// metaFromFileName 从文件名中识别书名和作者
+func metaFromFileName(name string) (book dao.Book) {
+	name = strings.TrimSpace(name)
+	book.Title = name
+	var m []string
+	if m = _fileNameBracket.FindStringSubmatch(name); m == nil {
+		if m = _fileNameAuthor.FindStringSubmatch(name); m == nil {
+			if m = _fileNameBy.FindStringSubmatch(name); m == nil {
+				if m = _fileNameSep.FindStringSubmatch(name); m != nil && !(hasHan(m[1]) && hasHan(m[2])) {
+					m = nil
+				}
+			}
+		}
+	}
+	if m != nil {
+		book.Title, book.Author = strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+	}
+	return
+}
+
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// metaFromHeader 识别txt开头"作者：xxx"形式的元数据
+func metaFromHeader(lines []string) (book dao.Book) {
+	for _, line := range lines[:min(len(lines), _headerLines)] {
+		m := _headerField.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := strings.Trim(m[2], "《》 ")
+		switch _headerKeys[strings.ToLower(strings.TrimSpace(m[1]))] {
+		case "title":
+			book.Title = value
+		case "author":
+			book.Author = value
+		case "series":
+			book.Series = value
+		case "language":
+			book.Language = value
+		case "publisher":
+			book.Publisher = value
+		case "description":
+			book.Description = value
+		case "tags":
+			book.Tags = strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || r == '，' || r == '、' || r == ' ' || r == '/'
+			}), ",")
+		}
+	}
+	return
+}
+
+// mergeMeta 用src补全dst中为空的字段
+func mergeMeta(dst dao.Book, src dao.Book) dao.Book {
+	fill := func(d *string, s string) {
+		if *d == "" {
+			*d = s
+		}
+	}
+	fill(&dst.Title, src.Title)
+	fill(&dst.Author, src.Author)
+	fill(&dst.Series, src.Series)
+	fill(&dst.Language, src.Language)
+	fill(&dst.Publisher, src.Publisher)
+	fill(&dst.Description, src.Description)
+	fill(&dst.Tags, src.Tags)
+	if dst.SeriesIndex == 0 {
+		dst.SeriesIndex = src.SeriesIndex
+	}
+	return dst
+}
+
+// safeTitle 书名用作文件名，去掉路径分隔符等字符
+func safeTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, title)
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// FormatSeries 系列名及序号
+func FormatSeries(book dao.Book) string {
+	if book.Series == "" {
+		return ""
+	}
+	if book.SeriesIndex == 0 {
+		return book.Series
+	}
+	return book.Series + " #" + strconv.FormatFloat(book.SeriesIndex, 'f', -1, 64)
+}
+
+type metadataMsg struct {
+	title string
+}
+
+func metadataCmd(title string) tea.Cmd {
+	return func() tea.Msg {
+		return metadataMsg{title: title}
+	}
+}
+
+type keyMapMetadata struct {
+	Next key.Binding
+	Prev key.Binding
+	Save key.Binding
+	Back key.Binding
+}
+
+var _keysMetadata = keyMapMetadata{
+	Next: key.NewBinding(
+		key.WithKeys("tab", "down"),
+		key.WithHelp("tab", "next"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("shift+tab", "up"),
+		key.WithHelp("shift+tab", "prev"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("enter", "ctrl+s"),
+		key.WithHelp("enter", "save"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+// 表单中的字段
+const (
+	metaAuthor = iota
+	metaSeries
+	metaSeriesIndex
+	metaLanguage
+	metaPublisher
+	metaTags
+	metaDescription
+)
+
+var _metaLabels = []string{"Author", "Series", "Series #", "Language", "Publisher", "Tags", "Description"}
+
+var _metaLabelStyle = lipgloss.NewStyle().Width(13).Foreground(lipgloss.Color("244"))
+
+type modelMetadata struct {
+	book   dao.Book
+	inputs []textinput.Model
+	focus  int
+	help   help.Model
+}
+
+func (m modelMetadata) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelMetadata) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		for i := range m.inputs {
+			m.inputs[i].Width = max(msg.Width-_metaLabelStyle.GetWidth()-10, 10)
+		}
+		return m, nil
+	case metadataMsg:
+		book, err := dao.GetBookByName(msg.title)
+		if err != nil {
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Open " + msg.title + " failed", Confirm: "OK"})
+		}
+		m.book = book
+		values := []string{book.Author, book.Series, "", book.Language, book.Publisher, book.Tags, book.Description}
+		if book.SeriesIndex != 0 {
+			values[metaSeriesIndex] = strconv.FormatFloat(book.SeriesIndex, 'f', -1, 64)
+		}
+		for i := range m.inputs {
+			m.inputs[i].SetValue(values[i])
+			m.inputs[i].Blur()
+		}
+		m.focus = 0
+		_typing = true
+		return m, m.inputs[0].Focus()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, _keysMetadata.Back):
+			_typing = false
+			return m, viewCmd(viewShelf)
+		case key.Matches(msg, _keysMetadata.Save):
+			return m.save()
+		case key.Matches(msg, _keysMetadata.Next, _keysMetadata.Prev):
+			m.inputs[m.focus].Blur()
+			if key.Matches(msg, _keysMetadata.Next) {
+				m.focus = (m.focus + 1) % len(m.inputs)
+			} else {
+				m.focus = (m.focus - 1 + len(m.inputs)) % len(m.inputs)
+			}
+			return m, m.inputs[m.focus].Focus()
+		}
+		m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m modelMetadata) save() (tea.Model, tea.Cmd) {
+	book := m.book
+	book.Author = strings.TrimSpace(m.inputs[metaAuthor].Value())
+	book.Series = strings.TrimSpace(m.inputs[metaSeries].Value())
+	book.SeriesIndex = 0
+	if index := strings.TrimSpace(m.inputs[metaSeriesIndex].Value()); index != "" {
+		var err error
+		book.SeriesIndex, err = strconv.ParseFloat(index, 64)
+		if err != nil {
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Invalid series number", Confirm: "OK"})
+		}
+	}
+	book.Language = strings.TrimSpace(m.inputs[metaLanguage].Value())
+	book.Publisher = strings.TrimSpace(m.inputs[metaPublisher].Value())
+	book.Tags = strings.Join(dao.Book{Tags: m.inputs[metaTags].Value()}.TagList(), ",")
+	book.Description = strings.TrimSpace(m.inputs[metaDescription].Value())
+	if err := dao.UpdateBookMetadata(book); err != nil {
+		return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Save Failed", Confirm: "OK"})
+	}
+	_typing = false
+	return m, tea.Batch(viewCmd(viewShelf), shelfCmd(shelfMsg{msg: "refresh"}))
+}
+
+func (m modelMetadata) View() string {
+	s := titleStyle.Render("Book Info") + subTitleStyle.Render(m.book.Title) + "\n\n"
+	for i, input := range m.inputs {
+		s += _metaLabelStyle.Render(_metaLabels[i]) + input.View() + "\n"
+	}
+	if m.book.SourcePath != "" {
+		s += "\n" + _metaLabelStyle.Render("Source") + subTitleStyle.UnsetPadding().Render(m.book.SourcePath) + "\n"
+	}
+	s += "\n" + m.help.ShortHelpView([]key.Binding{_keysMetadata.Next, _keysMetadata.Prev, _keysMetadata.Save, _keysMetadata.Back})
+	return _docStyle.Render(s)
+}
+
+func NewMetadata() modelMetadata {
+	inputs := make([]textinput.Model, len(_metaLabels))
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Prompt = ""
+	}
+	inputs[metaTags].Placeholder = "comma separated"
+	inputs[metaLanguage].Placeholder = "zh, en, ja..."
+	return modelMetadata{inputs: inputs, help: help.New()}
+}
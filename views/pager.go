@@ -5,11 +5,15 @@ package views
 
 import (
 	"fmt"
-	"math"
+	"sort"
+	"strconv"
 	"strings"
 
+	"go-reader/dao"
+
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -21,6 +25,7 @@ type pagerMsg struct {
 	content      string
 	currentIndex int
 	lastPos      int
+	col          int // lastPos行内的字节偏移，用于定位到长段落中间的页
 }
 
 func pagerCmd(pm pagerMsg) tea.Cmd {
@@ -29,11 +34,33 @@ func pagerCmd(pm pagerMsg) tea.Cmd {
 	}
 }
 
+// pagerRefreshMsg 标注等改变后重新渲染当前页
+type pagerRefreshMsg struct{}
+
+func pagerRefreshCmd() tea.Cmd {
+	return func() tea.Msg {
+		return pagerRefreshMsg{}
+	}
+}
+
 type keyMapPager struct {
-	PageUp   key.Binding
-	PageDown key.Binding
-	OpenDir  key.Binding
-	Quit     key.Binding
+	PageUp       key.Binding
+	PageDown     key.Binding
+	OpenDir      key.Binding
+	AddBookmark  key.Binding
+	OpenBookmark key.Binding
+	Search       key.Binding
+	Select       key.Binding
+	Annotations  key.Binding
+	AutoPage     key.Binding
+	ScrollMode   key.Binding
+	Theme        key.Binding
+	Convert      key.Binding
+	Options      key.Binding
+	NextHit      key.Binding
+	PrevHit      key.Binding
+	Help         key.Binding
+	Quit         key.Binding
 }
 
 var _keysPager = keyMapPager{
@@ -49,18 +76,122 @@ var _keysPager = keyMapPager{
 		key.WithKeys("d"),
 		key.WithHelp("d", "open dir"),
 	),
+	AddBookmark: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "bookmark"),
+	),
+	OpenBookmark: key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "bookmarks"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "highlight"),
+	),
+	Annotations: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "annotations"),
+	),
+	AutoPage: _keysAutoPage.Toggle,
+	ScrollMode: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "scroll/page"),
+	),
+	Theme: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "theme"),
+	),
+	Convert: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "简/繁"),
+	),
+	Options: _keysPagerOptions.Toggle,
+	NextHit: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n/N", "next/prev hit"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "more"),
+	),
+	PrevHit: key.NewBinding(
+		key.WithKeys("N"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("esc", "q"),
 		key.WithHelp("q", "quit"),
 	),
 }
 
+var _keysPagerSelect = struct {
+	Left    key.Binding
+	Right   key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Confirm key.Binding
+	Cancel  key.Binding
+}{
+	Left:    key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←↓↑→/hjkl", "extend")),
+	Right:   key.NewBinding(key.WithKeys("right", "l")),
+	Up:      key.NewBinding(key.WithKeys("up", "k")),
+	Down:    key.NewBinding(key.WithKeys("down", "j")),
+	Confirm: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "highlight")),
+	Cancel:  key.NewBinding(key.WithKeys("esc", "v"), key.WithHelp("esc", "cancel")),
+}
+
+// 滚动模式下按行、半屏滚动
+var _keysPagerScroll = struct {
+	LineUp   key.Binding
+	LineDown key.Binding
+	HalfUp   key.Binding
+	HalfDown key.Binding
+}{
+	LineUp:   key.NewBinding(key.WithKeys("up", "k")),
+	LineDown: key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↑/↓", "line")),
+	HalfUp:   key.NewBinding(key.WithKeys("ctrl+u")),
+	HalfDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+u/d", "half page")),
+}
+
+var _keysPagerSearch = struct {
+	Submit      key.Binding
+	Cancel      key.Binding
+	ToggleRegex key.Binding
+}{
+	Submit:      key.NewBinding(key.WithKeys("enter")),
+	Cancel:      key.NewBinding(key.WithKeys("esc")),
+	ToggleRegex: key.NewBinding(key.WithKeys("ctrl+r")),
+}
+
 func (k keyMapPager) ShortHelp() []key.Binding {
-	return []key.Binding{k.PageUp, k.PageDown, k.OpenDir, k.Quit}
+	bindings := []key.Binding{k.PageUp, k.PageDown, k.Search}
+	if len(searchHits) > 0 {
+		bindings = append(bindings, k.NextHit)
+	}
+	return append(bindings, k.Help, k.Quit)
 }
 
+// FullHelp 按?显示的全部按键，不常用的按键只在这里显示
 func (k keyMapPager) FullHelp() [][]key.Binding {
-	return [][]key.Binding{}
+	fullHelp := key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "short help"))
+	scroll := []key.Binding{k.ScrollMode, k.AutoPage}
+	if pagerScroll {
+		scroll = append(scroll, _keysPagerScroll.LineDown, _keysPagerScroll.HalfDown)
+	}
+	search := []key.Binding{k.Search, k.Select, k.Annotations}
+	if len(searchHits) > 0 {
+		search = append(search, k.NextHit)
+	}
+	return [][]key.Binding{
+		{k.PageUp, k.PageDown, k.OpenDir, k.Quit},
+		{k.AddBookmark, k.OpenBookmark},
+		search,
+		scroll,
+		{k.Theme, k.Convert, k.Options, fullHelp},
+	}
 }
 
 type modelPager struct {
@@ -69,58 +200,198 @@ type modelPager struct {
 	ready        bool
 	help         help.Model
 	viewport     viewport.Model
+	search       textinput.Model
+	searchRegex  bool
+	note         textinput.Model // 标注的笔记
+	auto         autoPage
+	options      bool // 正在调整排版设置
+	optionIndex  int
 }
 
 func (m modelPager) Init() tea.Cmd {
 	return nil
 }
 
+// pageLine 分页后的一行，src为其在章节内容中的行号，col为其在源行中的起始字节
+type pageLine struct {
+	text   string
+	src    int
+	col    int
+	prefix string // 首行缩进，不属于源行
+	hyphen bool   // 单词在行尾断开，显示连字符
+}
+
 var pageTotal = 1   // 总页数
 var currentPage = 1 // 当前页
 var jump = 0        // 跳转页数
 var posMapOffset = make(map[int]int)
+var pageLines []pageLine   // 当前章节分页后的所有行
+var procLines []string     // 当前章节的源行
+var pagerChapterIndex = -1 // 当前分页的章节
+var procWidth int          // 分页时每页的宽度
+
+// 滚动模式，按行滚动，本章之后连续显示下一章的开头，保存在设置中
+const _settingPagerScroll = "pager.scroll"
+
+const _wheelLines = 3 // 鼠标滚轮每次滚动的行数
+
+var _nextTitleStyle = lipgloss.NewStyle().Bold(true)
+
+var pagerScroll bool
+var jumpLine = 0         // 滚动模式下proc后显示在顶部的行
+var nextTitle string     // 下一章的章节名
+var nextLines []pageLine // 下一章开头的一屏
 
 /** 处理文章内容，使其适应屏幕宽度和高度 */
-func proc(content string, maxWidth int, maxHeight int, offset int) string {
-	// pos需要+1，因为第一行是章节名
-	posMapOffset[1] = 1
-	maxWidth -= 2
+func proc(content string, maxWidth int, maxHeight int, offset int, col int) string {
+	pagerSpread = spreadOn(maxWidth)
+	procWidth = pageWidth(maxWidth)
+	spreadGutter = maxWidth - 2*procWidth
+	if maxHeight < 1 {
+		maxHeight = 1
+	}
+	procHeight = maxHeight
 	// 按照换行符分割字符串
 	lines := strings.Split(content, "\n")
 	// 将超出最大宽度的行进行分割
-	newLines := make([]string, 0)
-	for i, line := range lines {
-		for runewidth.StringWidth(line) > maxWidth {
-			cut := runewidth.Truncate(line, maxWidth, "")
-			newLines = append(newLines, cut)
-			line = strings.TrimPrefix(line, cut)
-		}
-		newLines = append(newLines, line)
-
-		newLinesLen := len(newLines)
-		if newLinesLen%maxHeight == 0 {
-			posMapOffset[newLinesLen/maxHeight] = i + 1
-		}
-	}
-	// 如果总行数不是最大高度的倍数，补充空行
+	newLines := wrapLines(lines, procWidth)
+	// 如果总行数不是最大高度的倍数，补充空行，滚动模式不需要
 	ac := len(newLines) % maxHeight
-	if ac != 0 {
+	if ac != 0 && !pagerScroll {
 		for i := 0; i < maxHeight-ac; i++ {
-			newLines = append(newLines, "")
+			newLines = append(newLines, pageLine{src: len(lines), col: 0})
 		}
 	}
-	pageTotal = len(newLines) / maxHeight
-	posMapOffset[pageTotal] = len(lines)
+	pageLines, procLines = newLines, lines
+	pageTotal = (len(newLines) + maxHeight - 1) / maxHeight
+
+	// 每页第一行对应的位置，pos需要+1，因为第一行是章节名
+	posMapOffset = make(map[int]int)
+	for page := 1; page <= pageTotal; page++ {
+		posMapOffset[page] = min(newLines[(page-1)*maxHeight].src, len(lines)-1) + 1
+	}
+
+	nextTitle, nextLines = "", nil
+	if next := pagerChapterIndex + 1; pagerScroll && next < len(bookDirs) {
+		_, nextContent, _ := GetBookContent(GetChapterStart(next))
+		nextTitle = bookDirs[next].name
+		nextLines = wrapLines(strings.Split(nextContent, "\n"), procWidth)
+		nextLines = nextLines[:min(len(nextLines), maxHeight)]
+	}
 
 	currentPage = 1
 	// 查看pos在第几页
-	if offset > 0 && offset < len(lines) {
-		jump = int(math.Floor(float64(offset)/float64(maxHeight))) + 1
-	} else if offset >= len(lines) {
-		jump = pageTotal
+	jump, jumpLine = 1, 0
+	if offset > 0 && offset <= len(lines) {
+		jumpLine = lineOf(offset-1, col)
+		jump = jumpLine/maxHeight + 1
+	} else if offset > len(lines) {
+		// 滚动模式下为下一章的章节名显示在顶部
+		jump, jumpLine = pageTotal, len(newLines)
 	}
 
-	return strings.Join(newLines, "\n")
+	return renderPageLines()
+}
+
+// wrapLines 按避头尾规则和单词边界将行分割为宽度为width的屏幕上显示的行，
+// 并按排版设置加上首行缩进和段落间的空行
+func wrapLines(lines []string, width int) []pageLine {
+	maxWidth := typo.textWidth(width)
+	// 正文右边的空白，可以悬挂标点
+	hang := width - typo.leftPad(width) - maxWidth
+	newLines := make([]pageLine, 0, len(lines))
+	for i, line := range lines {
+		start, prefix := typo.paragraphIndent(line)
+		line = line[start:]
+		for {
+			n, hyphen := len(line), false
+			// 缩进比正文还宽时每行至少放一个字符
+			if lineWidth := max(maxWidth-runewidth.StringWidth(prefix), 1); runewidth.StringWidth(line) > lineWidth {
+				n, hyphen = breakLine(line, lineWidth, hang, typo.hyphenate)
+			}
+			newLines = append(newLines, pageLine{text: line[:n], src: i, col: start, prefix: prefix, hyphen: hyphen})
+			// 下一行不以空格开头
+			rest := strings.TrimLeft(line[n:], " ")
+			start += len(line) - len(rest)
+			line, prefix = rest, ""
+			if line == "" {
+				break
+			}
+		}
+		// 相邻两段之间空一行，原文已有空行时不再添加
+		if typo.paraSpacing && i+1 < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.TrimSpace(lines[i+1]) != "" {
+			newLines = append(newLines, pageLine{src: i, col: len(lines[i])})
+		}
+	}
+	return newLines
+}
+
+func (l pageLine) hyphenMark() string {
+	if l.hyphen {
+		return "-"
+	}
+	return ""
+}
+
+// lineOf 返回源行src中字节偏移col所在的分页后的行
+func lineOf(src int, col int) int {
+	i := sort.Search(len(pageLines), func(i int) bool {
+		l := pageLines[i]
+		return l.src > src || (l.src == src && l.col+len(l.text) > col)
+	})
+	return min(i, max(len(pageLines)-1, 0))
+}
+
+// lineAnchor 返回分页后第i行对应的源行和字节偏移，超出本章时为下一章的章节名
+func lineAnchor(i int) (src int, col int) {
+	if i < 0 {
+		return 0, 0
+	}
+	if i >= len(pageLines) {
+		return len(procLines), 0
+	}
+	return pageLines[i].src, pageLines[i].col
+}
+
+// lineAnchorPos 分页后第i行在书中的行号
+func lineAnchorPos(i int) int {
+	if i >= len(pageLines) {
+		return GetChapterStart(pagerChapterIndex + 1)
+	}
+	src, _ := lineAnchor(i)
+	return GetChapterStart(pagerChapterIndex) + min(src, len(procLines)-1) + 1
+}
+
+// renderPageLines 拼接分页后的行，显示标注、选中的文字和搜索结果
+func renderPageLines() string {
+	chapterStart := chapterContentStart()
+	lines := make([]string, len(pageLines))
+	for i, l := range pageLines {
+		if l.src < len(procLines) {
+			src, line := procLines[l.src], chapterStart+l.src
+			spans := highlightSpans(line, l.col, len(l.text), len(src))
+			spans = append(spans, selectionSpans(i, l.text)...)
+			spans = append(spans, searchSpans(src, line, l.col, len(l.text))...)
+			lines[i] = typo.paint(l.prefix) + decorate(Convert(l.text, bookConvert), spans) + typo.paint(l.hyphenMark())
+		}
+		lines[i] = typo.paintLine(lines[i], procWidth)
+	}
+	if nextTitle != "" {
+		style, _ := typo.style()
+		lines = append(lines, typo.paintLine(style.Copy().Inherit(_nextTitleStyle).Render(Convert(nextTitle, bookConvert)), procWidth))
+		for _, l := range nextLines {
+			lines = append(lines, typo.paintLine(typo.paint(l.prefix+Convert(l.text, bookConvert)+l.hyphenMark()), procWidth))
+		}
+	}
+	if pagerSpread {
+		lines = spreadLines(lines)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// chapterContentStart 当前章节内容第一行在bookAll中的行号
+func chapterContentStart() int {
+	return GetChapterStart(pagerChapterIndex) + 1
 }
 
 func (m modelPager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -134,53 +405,133 @@ func (m modelPager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.auto.on {
+			switch {
+			case key.Matches(msg, _keysAutoPage.Faster):
+				m.auto.adjust(false)
+				return m, nil
+			case key.Matches(msg, _keysAutoPage.Slower):
+				m.auto.adjust(true)
+				return m, nil
+			}
+			// 任意键暂停自动翻页，再按a时只暂停
+			m.auto.stop()
+			if key.Matches(msg, _keysPager.AutoPage) {
+				return m, nil
+			}
+		}
+		if m.search.Focused() {
+			return m.updateSearch(msg)
+		}
+		if m.note.Focused() {
+			return m.updateNote(msg)
+		}
+		if selecting {
+			return m.updateSelect(msg)
+		}
+		if m.options {
+			return m.updateOptions(msg)
+		}
 		switch {
 		case key.Matches(msg, _keysPager.Quit):
+			// 书架刷新时读取LastPos，先保存防抖中的阅读位置
+			updateBookPosDebounce.Flush()
+			endSession()
+			ClearSearch()
+			cmds = append(cmds, tea.DisableMouse)
 			cmds = append(cmds, shelfCmd(shelfMsg{msg: "refresh"}))
 			cmds = append(cmds, viewCmd(viewShelf))
 			return m, tea.Batch(cmds...)
+		case pagerScroll && key.Matches(msg, _keysPager.PageUp):
+			return m.scroll(-m.viewport.Height)
+		case pagerScroll && key.Matches(msg, _keysPagerScroll.LineUp):
+			return m.scroll(-1)
+		case pagerScroll && key.Matches(msg, _keysPagerScroll.LineDown):
+			return m.scroll(1)
+		case pagerScroll && key.Matches(msg, _keysPagerScroll.HalfUp):
+			return m.scroll(-m.viewport.Height / 2)
+		case pagerScroll && key.Matches(msg, _keysPagerScroll.HalfDown):
+			return m.scroll(m.viewport.Height / 2)
+		case key.Matches(msg, _keysPager.ScrollMode):
+			return m.toggleScroll()
+		case key.Matches(msg, _keysPager.Theme):
+			typo.theme = cycle(typo.theme, 1, len(_themes))
+			typo.save()
+			m.applyTheme()
+			return m, nil
+		case key.Matches(msg, _keysPager.Convert):
+			if err := SetBookConvert(nextConvert(bookConvert)); err != nil {
+				return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: err.Error(), Confirm: "OK"})
+			}
+			m.viewport.SetContent(renderPageLines())
+			return m, nil
+		case key.Matches(msg, _keysPager.Options):
+			m.options = true
+			return m, nil
+		case key.Matches(msg, _keysPager.Help):
+			// 页脚的高度改变，重新分页
+			m.help.ShowAll = !m.help.ShowAll
+			return m, m.relayout()
 		case key.Matches(msg, _keysPager.PageUp):
 			if currentPage <= 1 {
 				// 上一章
-				if m.currentIndex >= 0 {
+				if m.currentIndex > 0 || (m.currentIndex == 0 && bookDirs[0].start > 0) {
+					readPage(GetChapterStart(m.currentIndex)-1, false)
 					title, content, index := GetBookContent(GetChapterStart(m.currentIndex - 1))
 					cmds = append(cmds, pagerCmd(pagerMsg{title: title, content: content, lastPos: GetChapterStart(m.currentIndex) - 1, currentIndex: index}))
 					return m, tea.Batch(cmds...)
 				}
 				return m, nil
 			}
-			currentPage--
+			m.showPage(currentPage - pageStep())
+			readPage(GetChapterStart(m.currentIndex)+posMapOffset[currentPage], false)
 			UpdateBookPos(bookName, GetChapterStart(m.currentIndex)+posMapOffset[currentPage])
-			nextmsg := tea.KeyMsg{Type: tea.KeyPgUp}
-			m.viewport, cmd = m.viewport.Update(nextmsg)
-			return m, cmd
+			return m, nil
 		case key.Matches(msg, _keysPager.PageDown):
-			if currentPage >= pageTotal {
-				// 下一章
-				if m.currentIndex < len(bookDirs)-1 {
-					title, content, index := GetBookContent(GetChapterStart(m.currentIndex + 1))
-					cmds = append(cmds, pagerCmd(pagerMsg{title: title, content: content, lastPos: GetChapterStart(m.currentIndex + 1), currentIndex: index}))
-					return m, tea.Batch(cmds...)
-				}
-				return m, nil
-			}
-			currentPage++
-			UpdateBookPos(bookName, GetChapterStart(m.currentIndex)+posMapOffset[currentPage])
-			nextmsg := tea.KeyMsg{Type: tea.KeyPgDown}
-			m.viewport, cmd = m.viewport.Update(nextmsg)
+			m, cmd, _ = m.pageDown()
 			return m, cmd
 		case key.Matches(msg, _keysPager.OpenDir):
 			cmds = append(cmds, dirCmd(DirMsg{index: m.currentIndex}))
 			cmds = append(cmds, viewCmd(viewDirList))
 			return m, tea.Batch(cmds...)
+		case key.Matches(msg, _keysPager.AddBookmark):
+			if _, err := AddBookmark(m.currentIndex); err != nil {
+				return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Add bookmark failed", Confirm: "OK"})
+			}
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Bookmark added", Confirm: "OK"})
+		case key.Matches(msg, _keysPager.OpenBookmark):
+			return m, tea.Batch(bookmarkCmd(), viewCmd(viewBookmark))
+		case key.Matches(msg, _keysPager.Search):
+			m.search.SetValue(searchQuery)
+			m.search.CursorEnd()
+			_typing = true
+			return m, m.search.Focus()
+		case key.Matches(msg, _keysPager.Select):
+			if startSelection(m.topLine(), m.viewport.Height*pageStep()) {
+				m.viewport.SetContent(renderPageLines())
+			}
+			return m, nil
+		case key.Matches(msg, _keysPager.Annotations):
+			return m, tea.Batch(annotationCmd(), viewCmd(viewAnnotation))
+		case key.Matches(msg, _keysPager.AutoPage):
+			return m, m.auto.start()
+		case key.Matches(msg, _keysPager.NextHit, _keysPager.PrevHit):
+			// 从当前结果(或当前页)开始查找下一个结果，可跨章节
+			line, col := lineAnchorPos(m.topLine()), -1
+			if searchIndex >= 0 && searchIndex < len(searchHits) {
+				line, col = searchHits[searchIndex].line, searchHits[searchIndex].start
+			}
+			return m, jumpToHit(nextHit(line, col, key.Matches(msg, _keysPager.NextHit)))
 		default:
 			return m, nil
 		}
 
 	case tea.WindowSizeMsg:
-
+		// 帮助超出宽度时截断，页脚的高度不随宽度变化
+		m.help.Width = msg.Width
+		verticalMarginHeight = headerHeight + lipgloss.Height(m.footerView())
 		if !m.ready {
-			m.content = proc(m.content, msg.Width, msg.Height-verticalMarginHeight, 0)
+			content := proc(m.content, msg.Width, msg.Height-verticalMarginHeight, 0, 0)
 			// Since this program is using the full size of the viewport we
 			// need to wait until we've received the window dimensions before
 			// we can initialize the viewport. The initial dimensions come in
@@ -188,7 +539,7 @@ func (m modelPager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// here.
 			m.viewport = viewport.New(msg.Width, msg.Height-verticalMarginHeight)
 			m.viewport.YPosition = headerHeight
-			m.viewport.SetContent(m.content)
+			m.viewport.SetContent(content)
 			m.viewport.MouseWheelEnabled = false
 			m.ready = true
 
@@ -199,20 +550,63 @@ func (m modelPager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.YPosition = headerHeight + 1
 
 		} else {
+			// 按新的宽高重新分页，停留在原来页首所在的源行，不改变LastPos
+			cancelSelection()
+			src, col := lineAnchor(m.topLine())
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - verticalMarginHeight
+			m.viewport.SetContent(proc(m.content, msg.Width, m.viewport.Height, src+1, col))
+		}
+	case tea.MouseMsg:
+		// 鼠标消息会发给所有界面，只在阅读时处理
+		if !pagerScroll || _curView != viewPager || selecting || !m.ready {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			return m.scroll(-_wheelLines)
+		case tea.MouseButtonWheelDown:
+			return m.scroll(_wheelLines)
+		}
+		return m, nil
+	case autoTickMsg:
+		if !m.auto.on || msg.id != m.auto.id {
+			return m, nil
+		}
+		if !m.auto.tick() {
+			return m, autoTickCmd(msg.id)
 		}
+		var moved bool
+		m, cmd, moved = m.pageDown()
+		if !moved {
+			// 已经是最后一页
+			m.auto.stop()
+			return m, cmd
+		}
+		return m, tea.Batch(cmd, autoTickCmd(msg.id))
+	case pagerRefreshMsg:
+		if m.ready {
+			m.viewport.SetContent(renderPageLines())
+		}
+		return m, nil
 	case pagerMsg:
 		headerHeight := lipgloss.Height(m.headerView())
 		footerHeight := lipgloss.Height(m.footerView())
 		verticalMarginHeight := headerHeight + footerHeight
 		m.currentIndex = msg.currentIndex
+		pagerChapterIndex = msg.currentIndex
+		cancelSelection()
 		m.title = msg.title
-		m.content = proc(msg.content, winwidth, winheight-verticalMarginHeight, msg.lastPos-GetChapterStart(m.currentIndex))
+		m.content = msg.content
 		m.viewport = viewport.New(winwidth, winheight-verticalMarginHeight)
 		m.viewport.YPosition = headerHeight
-		m.viewport.SetContent(m.content)
+		m.viewport.SetContent(proc(m.content, winwidth, winheight-verticalMarginHeight, msg.lastPos-GetChapterStart(m.currentIndex), msg.col))
 		m.viewport.MouseWheelEnabled = false
+		m.applyTheme()
+		touchSession()
+		if pagerScroll {
+			cmds = append(cmds, tea.EnableMouseCellMotion)
+		}
 		if msg.lastPos > 0 {
 			UpdateBookPos(bookName, msg.lastPos)
 		} else {
@@ -223,19 +617,251 @@ func (m modelPager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle keyboard and mouse events in the viewport
 	m.viewport, cmd = m.viewport.Update(msg)
 	cmds = append(cmds, cmd)
-	if jump > 1 {
-		for i := 2; i <= jump; i++ {
-			msg := tea.KeyMsg{Type: tea.KeyPgDown}
-			m.viewport, cmd = m.viewport.Update(msg)
-			cmds = append(cmds, cmd)
+	if jump > 0 {
+		if pagerScroll {
+			m.viewport.SetYOffset(jumpLine)
+			currentPage = m.viewport.YOffset/max(m.viewport.Height, 1) + 1
+		} else {
+			m.showPage(jump)
 		}
-		currentPage = jump
-		jump = 0
 	}
+	jump = 0
 
 	return m, tea.Batch(cmds...)
 }
 
+// pageDown 下一页，在章节最后一页时进入下一章，已经是全书最后一页时moved为false
+func (m modelPager) pageDown() (_ modelPager, cmd tea.Cmd, moved bool) {
+	if pagerScroll {
+		offset := m.viewport.YOffset
+		m, cmd = m.scroll(m.viewport.Height)
+		return m, cmd, cmd != nil || m.viewport.YOffset != offset
+	}
+	if currentPage+pageStep() > pageTotal {
+		// 下一章
+		if m.currentIndex < len(bookDirs)-1 {
+			readPage(GetChapterStart(m.currentIndex+1), true)
+			title, content, index := GetBookContent(GetChapterStart(m.currentIndex + 1))
+			return m, pagerCmd(pagerMsg{title: title, content: content, lastPos: GetChapterStart(m.currentIndex + 1), currentIndex: index}), true
+		}
+		return m, nil, false
+	}
+	m.showPage(currentPage + pageStep())
+	readPage(GetChapterStart(m.currentIndex)+posMapOffset[currentPage], true)
+	UpdateBookPos(bookName, GetChapterStart(m.currentIndex)+posMapOffset[currentPage])
+	return m, nil, true
+}
+
+// scroll 滚动模式下移动n行，越过本章末尾或开头时接着显示相邻的章节
+func (m modelPager) scroll(n int) (modelPager, tea.Cmd) {
+	y := m.viewport.YOffset + n
+	switch {
+	case y > len(pageLines) && nextTitle != "":
+		// 本章内容和下一章的章节名都已移出顶部，顶部为下一章的第i行
+		next := m.currentIndex + 1
+		i := min(y-len(pageLines)-1, len(nextLines)-1)
+		pos, col := GetChapterStart(next)+1, 0
+		if i >= 0 {
+			pos, col = GetChapterStart(next)+nextLines[i].src+1, nextLines[i].col
+		}
+		readScroll(pos, n, m.viewport.Height)
+		title, content, index := GetBookContent(GetChapterStart(next))
+		return m, pagerCmd(pagerMsg{title: title, content: content, lastPos: pos, col: col, currentIndex: index})
+	case y < 0 && (m.currentIndex > 0 || (m.currentIndex == 0 && bookDirs[0].start > 0)):
+		// 上一章的最后几行，y为-1时顶部是本章的章节名
+		prev := m.currentIndex - 1
+		title, content, index := GetBookContent(GetChapterStart(prev))
+		lines := strings.Split(content, "\n")
+		wrapped := wrapLines(lines, m.viewport.Width)
+		pos, col := GetChapterStart(m.currentIndex), 0
+		if i := len(wrapped) + y + 1; i < len(wrapped) {
+			l := wrapped[max(i, 0)]
+			pos, col = GetChapterStart(prev)+min(l.src, len(lines)-1)+1, l.col
+		}
+		readScroll(pos, n, m.viewport.Height)
+		return m, pagerCmd(pagerMsg{title: title, content: content, lastPos: pos, col: col, currentIndex: index})
+	}
+	m.viewport.SetYOffset(y)
+	currentPage = m.viewport.YOffset/max(m.viewport.Height, 1) + 1
+	pos := lineAnchorPos(m.topLine())
+	readScroll(pos, n, m.viewport.Height)
+	UpdateBookPos(bookName, pos)
+	return m, nil
+}
+
+// toggleScroll 切换滚动和翻页模式，保持顶部的行不变
+func (m modelPager) toggleScroll() (modelPager, tea.Cmd) {
+	pagerScroll = !pagerScroll
+	dao.SetSetting(_settingPagerScroll, strconv.FormatBool(pagerScroll))
+	cancelSelection()
+	pos := lineAnchorPos(m.topLine())
+	_, col := lineAnchor(m.topLine())
+	mouse := tea.DisableMouse
+	if pagerScroll {
+		mouse = tea.EnableMouseCellMotion
+	}
+	title, content, index := GetBookContent(pos)
+	return m, tea.Batch(mouse, pagerCmd(pagerMsg{title: title, content: content, lastPos: pos, col: col, currentIndex: index}))
+}
+
+// showPage 翻到第page页，双页显示时为page所在的两页
+func (m *modelPager) showPage(page int) {
+	currentPage = spreadStart(min(max(page, 1), pageTotal))
+	m.viewport.SetYOffset((currentPage - 1) / pageStep() * m.viewport.Height)
+}
+
+// topLine 屏幕左上角的行在pageLines中的下标
+func (m modelPager) topLine() int {
+	if pagerSpread {
+		return (currentPage - 1) * m.viewport.Height
+	}
+	return m.viewport.YOffset
+}
+
+// applyTheme 使用当前配色重新渲染，配色不影响分页
+func (m *modelPager) applyTheme() {
+	m.viewport.Style = lipgloss.NewStyle()
+	if style, ok := typo.style(); ok {
+		// 内容不足一屏时空白部分也使用背景色
+		m.viewport.Style = lipgloss.NewStyle().Background(style.GetBackground())
+	}
+	m.viewport.SetContent(renderPageLines())
+}
+
+// relayout 排版设置改变后重新分页，保持顶部的行不变
+func (m modelPager) relayout() tea.Cmd {
+	cancelSelection()
+	pos := lineAnchorPos(m.topLine())
+	_, col := lineAnchor(m.topLine())
+	title, content, index := GetBookContent(pos)
+	return pagerCmd(pagerMsg{title: title, content: content, lastPos: pos, col: col, currentIndex: index})
+}
+
+// updateOptions 调整排版设置时的按键，每次改变立即生效
+func (m modelPager) updateOptions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, _keysPagerOptions.Close):
+		m.options = false
+	case key.Matches(msg, _keysPagerOptions.Prev):
+		m.optionIndex = cycle(m.optionIndex, -1, len(_pagerOptions))
+	case key.Matches(msg, _keysPagerOptions.Next):
+		m.optionIndex = cycle(m.optionIndex, 1, len(_pagerOptions))
+	case key.Matches(msg, _keysPagerOptions.Less, _keysPagerOptions.More):
+		delta := 1
+		if key.Matches(msg, _keysPagerOptions.Less) {
+			delta = -1
+		}
+		old := typo
+		_pagerOptions[m.optionIndex].change(delta)
+		typo.save()
+		status.save()
+		if typo.theme != old.theme {
+			m.applyTheme()
+		} else if typo != old {
+			return m, m.relayout()
+		}
+	}
+	return m, nil
+}
+
+// updateSelect 选择文字时的按键，光标移出当前页时翻页
+func (m modelPager) updateSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, _keysPagerSelect.Cancel):
+		cancelSelection()
+	case key.Matches(msg, _keysPagerSelect.Confirm):
+		m.note.SetValue("")
+		_typing = true
+		return m, m.note.Focus()
+	case key.Matches(msg, _keysPagerSelect.Left):
+		moveCursor(-1, 0)
+	case key.Matches(msg, _keysPagerSelect.Right):
+		moveCursor(1, 0)
+	case key.Matches(msg, _keysPagerSelect.Up):
+		moveCursor(0, -1)
+	case key.Matches(msg, _keysPagerSelect.Down):
+		moveCursor(0, 1)
+	default:
+		return m, nil
+	}
+	if pagerScroll {
+		// 光标移出屏幕时滚动到刚好可见
+		if selCursor.line < m.viewport.YOffset {
+			m.viewport.SetYOffset(selCursor.line)
+		} else if selCursor.line >= m.viewport.YOffset+m.viewport.Height {
+			m.viewport.SetYOffset(selCursor.line - m.viewport.Height + 1)
+		}
+	} else if page := selCursor.line/m.viewport.Height + 1; spreadStart(page) != currentPage {
+		m.showPage(page)
+	}
+	m.viewport.SetContent(renderPageLines())
+	return m, nil
+}
+
+// updateNote 输入标注的笔记，esc返回继续选择
+func (m modelPager) updateNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, _keysPagerSearch.Cancel):
+		m.note.Blur()
+		_typing = false
+		return m, nil
+	case key.Matches(msg, _keysPagerSearch.Submit):
+		m.note.Blur()
+		_typing = false
+		_, err := AddHighlight(strings.TrimSpace(m.note.Value()))
+		cancelSelection()
+		m.viewport.SetContent(renderPageLines())
+		if err != nil {
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "Add highlight failed", Confirm: "OK"})
+		}
+		return m, nil
+	}
+	m.note, cmd = m.note.Update(msg)
+	return m, cmd
+}
+
+// updateSearch 处理搜索输入框的按键
+func (m modelPager) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, _keysPagerSearch.Cancel):
+		m.search.Blur()
+		_typing = false
+		return m, nil
+	case key.Matches(msg, _keysPagerSearch.ToggleRegex):
+		m.searchRegex = !m.searchRegex
+		m.search.Prompt = searchPrompt(m.searchRegex)
+		return m, nil
+	case key.Matches(msg, _keysPagerSearch.Submit):
+		m.search.Blur()
+		_typing = false
+		query := m.search.Value()
+		if query == "" {
+			ClearSearch()
+			m.viewport.SetContent(renderPageLines())
+			return m, nil
+		}
+		if err := SearchBook(query, m.searchRegex); err != nil {
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: err.Error(), Confirm: "OK"})
+		}
+		if len(searchHits) == 0 {
+			m.viewport.SetContent(renderPageLines())
+			return m, dialogCmd(dialogMsg{Type: DialogAlert, Title: "No match for " + query, Confirm: "OK"})
+		}
+		return m, tea.Batch(searchCmd(), viewCmd(viewSearch))
+	}
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+func searchPrompt(useRegex bool) string {
+	if useRegex {
+		return "regex/"
+	}
+	return "/"
+}
+
 func (m modelPager) View() string {
 	if !m.ready {
 		return "\n  Loading..."
@@ -245,18 +871,56 @@ func (m modelPager) View() string {
 
 func (m modelPager) headerView() string {
 	s := "\n"
-	s += titleStyle.Render(bookName)
-	s += subTitleStyle.Render(m.title)
+	s += titleStyle.Render(Convert(bookName, bookConvert))
+	if m.currentIndex >= 0 {
+		s += subTitleStyle.Render(Convert(GetChapterPath(m.currentIndex), bookConvert))
+	} else {
+		s += subTitleStyle.Render(Convert(m.title, bookConvert))
+	}
+	if bookConvert != "" {
+		s += subTitleStyle.Render(ConvertLabel(bookConvert))
+	}
 	s += "\n"
 	return s
 }
 
 func (m modelPager) footerView() string {
-	return "\n" + m.help.View(_keysPager) + "\n"
+	return m.statusView() + "\n" + m.footerLine() + "\n"
+}
+
+// footerLine 状态栏下方的一行，显示输入框或按键帮助
+func (m modelPager) footerLine() string {
+	if m.search.Focused() {
+		return m.search.View()
+	}
+	if m.note.Focused() {
+		return m.note.View()
+	}
+	if selecting {
+		return m.help.ShortHelpView([]key.Binding{_keysPagerSelect.Left, _keysPagerSelect.Confirm, _keysPagerSelect.Cancel})
+	}
+	if m.options {
+		return optionView(m.optionIndex) + "  " + m.help.ShortHelpView([]key.Binding{_keysPagerOptions.Next, _keysPagerOptions.More, _keysPagerOptions.Close})
+	}
+	if m.auto.on {
+		return m.auto.indicator() + m.help.ShortHelpView([]key.Binding{_keysAutoPage.Faster, _keysAutoPage.Pause})
+	}
+	return m.help.View(_keysPager)
 }
 
 func NewPager() modelPager {
+	pagerScroll, _ = strconv.ParseBool(dao.GetSetting(_settingPagerScroll, "false"))
+	typo = loadTypography()
+	status = loadStatusItems()
+	search := textinput.New()
+	search.Prompt = searchPrompt(false)
+	search.Placeholder = "search (ctrl+r: regex)"
+	note := textinput.New()
+	note.Prompt = "note: "
+	note.Placeholder = "optional, enter to save"
 	return modelPager{
-		help: help.New(),
+		help:   help.New(),
+		search: search,
+		note:   note,
 	}
 }
@@ -0,0 +1,246 @@
+package views
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// searchHit 搜索结果，line为在bookAll中的行号，start/end为行内字节偏移
+type searchHit struct {
+	line, start, end int
+}
+
+const (
+	_maxSearchHits  = 10000 // 最多保留的搜索结果数
+	_snippetContext = 16    // 摘录中匹配前后保留的宽度
+)
+
+var (
+	searchQuery string
+	searchRe    *regexp.Regexp
+	searchHits  []searchHit
+	searchIndex = -1 // 当前所在的搜索结果
+
+	_matchStyle        = lipgloss.NewStyle().Background(lipgloss.Color("220")).Foreground(lipgloss.Color("0"))
+	_currentMatchStyle = lipgloss.NewStyle().Background(lipgloss.Color("208")).Foreground(lipgloss.Color("0"))
+)
+
+// compileSearch 普通模式按字面匹配，Latin字母不区分大小写
+// 查询和被搜索的文字都先转为简体，简体和繁体可以互相匹配
+func compileSearch(query string, useRegex bool) (*regexp.Regexp, error) {
+	query = foldScript(query)
+	if !useRegex {
+		query = regexp.QuoteMeta(query)
+	}
+	return regexp.Compile("(?i)" + query)
+}
+
+// SearchBook 在当前书中搜索，结果保存在searchHits中
+func SearchBook(query string, useRegex bool) error {
+	re, err := compileSearch(query, useRegex)
+	if err != nil {
+		return err
+	}
+	searchQuery, searchRe = query, re
+	searchHits = findHits(bookAll, re, _maxSearchHits)
+	searchIndex = -1
+	return nil
+}
+
+func ClearSearch() {
+	searchQuery, searchRe = "", nil
+	searchHits = nil
+	searchIndex = -1
+}
+
+func findHits(lines []string, re *regexp.Regexp, limit int) []searchHit {
+	hits := make([]searchHit, 0)
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(foldScript(line), -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			hits = append(hits, searchHit{line: i, start: loc[0], end: loc[1]})
+			if len(hits) >= limit {
+				return hits
+			}
+		}
+	}
+	return hits
+}
+
+// nextHit 返回pos(行号,字节偏移)之后(forward)或之前的第一个结果
+func nextHit(line int, col int, forward bool) int {
+	if len(searchHits) == 0 {
+		return -1
+	}
+	if forward {
+		for i, hit := range searchHits {
+			if hit.line > line || (hit.line == line && hit.start > col) {
+				return i
+			}
+		}
+		return 0
+	}
+	for i := len(searchHits) - 1; i >= 0; i-- {
+		hit := searchHits[i]
+		if hit.line < line || (hit.line == line && hit.start < col) {
+			return i
+		}
+	}
+	return len(searchHits) - 1
+}
+
+// hitSnippet 截取匹配前后的文字
+func hitSnippet(line string, start int, end int) string {
+	before := []rune(line[:start])
+	after := line[end:]
+	width := 0
+	i := len(before)
+	for i > 0 && width < _snippetContext {
+		i--
+		width += runewidth.RuneWidth(before[i])
+	}
+	prefix := string(before[i:])
+	if i > 0 {
+		prefix = "…" + prefix
+	}
+	suffix := runewidth.Truncate(after, _snippetContext, "…")
+	return prefix + "[" + line[start:end] + "]" + suffix
+}
+
+// searchSpans 返回src中[col,col+textLen)范围内的搜索结果，偏移相对于col
+func searchSpans(src string, line int, col int, textLen int) []span {
+	if searchRe == nil || textLen == 0 {
+		return nil
+	}
+	spans := make([]span, 0)
+	for _, loc := range searchRe.FindAllStringIndex(foldScript(src), -1) {
+		start, end := loc[0]-col, loc[1]-col
+		if end <= 0 || start >= textLen || loc[0] == loc[1] {
+			continue
+		}
+		style := _matchStyle
+		if searchIndex >= 0 && searchIndex < len(searchHits) {
+			if hit := searchHits[searchIndex]; hit.line == line && hit.start == loc[0] {
+				style = _currentMatchStyle
+			}
+		}
+		spans = append(spans, span{start: max(start, 0), end: min(end, textLen), style: style})
+	}
+	return spans
+}
+
+// jumpToHit 打开第index个搜索结果所在的页
+func jumpToHit(index int) tea.Cmd {
+	if index < 0 || index >= len(searchHits) {
+		return nil
+	}
+	searchIndex = index
+	hit := searchHits[index]
+	title, content, chapter := GetBookContent(hit.line)
+	return pagerCmd(pagerMsg{title: title, content: content, lastPos: hit.line, col: hit.start, currentIndex: chapter})
+}
+
+type searchMsg struct{}
+
+func searchCmd() tea.Cmd {
+	return func() tea.Msg {
+		return searchMsg{}
+	}
+}
+
+type keyMapSearch struct {
+	Back   key.Binding
+	Select key.Binding
+}
+
+var _keysSearch = keyMapSearch{
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("q", "back"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "jump"),
+	),
+}
+
+type itemHit struct {
+	index   int
+	chapter string
+	snippet string
+}
+
+func (i itemHit) Title() string       { return i.chapter }
+func (i itemHit) Description() string { return i.snippet }
+func (i itemHit) FilterValue() string { return i.snippet }
+
+type modelSearch struct {
+	list list.Model
+}
+
+func (m modelSearch) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelSearch) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := _docStyle.GetFrameSize()
+		m.list.SetSize(msg.Width-h, msg.Height-v)
+		return m, nil
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, _keysSearch.Back):
+			return m, viewCmd(viewPager)
+		case key.Matches(msg, _keysSearch.Select):
+			if item, ok := m.list.SelectedItem().(itemHit); ok {
+				return m, tea.Batch(jumpToHit(item.index), viewCmd(viewPager))
+			}
+			return m, nil
+		}
+	case searchMsg:
+		items := make([]list.Item, 0, len(searchHits))
+		for i, hit := range searchHits {
+			chapter := GetChapterPath(GetChapterIndex(hit.line))
+			if chapter == "" {
+				chapter = bookName
+			}
+			items = append(items, itemHit{index: i, chapter: chapter, snippet: hitSnippet(bookAll[hit.line], hit.start, hit.end)})
+		}
+		m.list.Title = "Search: " + searchQuery
+		if len(searchHits) >= _maxSearchHits {
+			m.list.Title += fmt.Sprintf(" (first %d)", _maxSearchHits)
+		}
+		cmd = m.list.SetItems(items)
+		m.list.Select(max(searchIndex, 0))
+		return m, cmd
+	}
+
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m modelSearch) View() string {
+	return _docStyle.Render(m.list.View())
+}
+
+func NewSearch() modelSearch {
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Search"
+	l.Styles.Title = titleStyle
+	l.SetFilteringEnabled(false)
+	l.SetStatusBarItemName("hit", "hits")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{_keysSearch.Select}
+	}
+	return modelSearch{list: l}
+}
@@ -0,0 +1,83 @@
+package views
+
+import (
+	"time"
+
+	"go-reader/dao"
+	"go-reader/utils"
+)
+
+// 超过该时间没有翻页时，下次翻页开始新的阅读记录
+const _sessionIdle = 5 * time.Minute
+
+// readingSession 当前的阅读记录，第一次翻页后才写入数据库
+var readingSession *dao.Session
+
+var saveSessionDebounce = utils.NewDebouncer(1000)
+
+// touchSession 打开书或跳转时调用，没有进行中的阅读记录时开始新的记录
+func touchSession() {
+	now := time.Now()
+	if readingSession != nil && readingSession.BookID == bookID && now.Sub(readingSession.EndAt) <= _sessionIdle {
+		return
+	}
+	endSession()
+	readingSession = &dao.Session{BookID: bookID, StartAt: now, EndAt: now, StartPos: bookPos, EndPos: bookPos}
+}
+
+// readPage 翻页时调用，pos为翻页后的位置
+func readPage(pos int, forward bool) {
+	pages := 0
+	if forward {
+		pages = 1
+	}
+	recordRead(pos, pages, forward)
+}
+
+// scrolledLines 滚动模式下向后滚动的行数，满一屏记为一页
+var scrolledLines int
+
+// readScroll 滚动模式下滚动n行后调用，pos为顶部的行
+func readScroll(pos int, n int, height int) {
+	height = max(height, 1)
+	if n > 0 {
+		scrolledLines += n
+	}
+	pages := scrolledLines / height
+	scrolledLines %= height
+	recordRead(pos, pages, n > 0)
+}
+
+func recordRead(pos int, pages int, forward bool) {
+	touchSession()
+	session := readingSession
+	session.Pages += pages
+	if forward && pos > session.EndPos {
+		session.Lines += pos - session.EndPos
+	}
+	session.EndPos = pos
+	session.EndAt = time.Now()
+	if session.ID == 0 {
+		dao.SaveSession(session)
+		return
+	}
+	saved := *session
+	saveSessionDebounce.Debounce(func() {
+		dao.SaveSession(&saved)
+	})
+}
+
+// endSession 离开阅读界面时结束阅读记录，最后一页的阅读时间也计入
+func endSession() {
+	session := readingSession
+	readingSession = nil
+	if session == nil || session.ID == 0 {
+		return
+	}
+	if now := time.Now(); now.Sub(session.EndAt) <= _sessionIdle {
+		session.EndAt = now
+	}
+	// 取消还未执行的保存，避免覆盖结束时间
+	saveSessionDebounce.Debounce(func() {})
+	dao.SaveSession(session)
+}
@@ -3,6 +3,7 @@ package views
 import (
 	"fmt"
 	"go-reader/dao"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -12,17 +13,27 @@ import (
 
 type itemShelf struct {
 	title, desc string
+	filter      string
 }
 
 type modelShelf struct {
 	Selected  itemShelf
 	Importing bool
 	list      list.Model
+	mode      shelfMode
 }
 type keyMapShelf struct {
 	Select key.Binding
 	Import key.Binding
 	Remove key.Binding
+	Search key.Binding
+	Info   key.Binding
+	Sort   key.Binding
+	Group  key.Binding
+	Filter key.Binding
+	Stats  key.Binding
+	EPUB   key.Binding
+	MD     key.Binding
 }
 type shelfMsg struct {
 	msg string
@@ -49,11 +60,43 @@ var _keysShelf = keyMapShelf{
 		key.WithKeys("r", "delete"), // "delete" is an alias for "r
 		key.WithHelp("r", "remove"),
 	),
+	Search: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "search all"),
+	),
+	Info: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit info"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "sort"),
+	),
+	Group: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "group"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "unfinished/unread/..."),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "statistics"),
+	),
+	EPUB: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "export epub"),
+	),
+	MD: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "export markdown"),
+	),
 }
 
 func (i itemShelf) Title() string       { return i.title }
 func (i itemShelf) Description() string { return i.desc }
-func (i itemShelf) FilterValue() string { return i.title }
+func (i itemShelf) FilterValue() string { return i.filter }
 
 func (m modelShelf) Init() tea.Cmd {
 	return nil
@@ -62,26 +105,17 @@ func (m modelShelf) Init() tea.Cmd {
 func (m modelShelf) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		itemLen := len(m.list.Items())
-		if itemLen > 0 {
+		// 过滤时按键作为输入
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if item, ok := m.list.SelectedItem().(itemShelf); ok {
 			switch {
 			case key.Matches(msg, _keysShelf.Select):
-				m.Selected = m.list.Items()[m.list.Index()].(itemShelf)
-				pos, err := ProcBook(m.Selected.title)
-				if err != nil {
-					return m, dialogCmd(dialogMsg{
-						Type:    DialogAlert,
-						Title:   "Open " + m.Selected.title + " failed",
-						Confirm: "OK",
-					})
-				}
-				title, content, index := GetBookContent(pos)
-				return m, tea.Batch(
-					pagerCmd(pagerMsg{title: title, content: content, lastPos: pos, currentIndex: index}),
-					viewCmd(viewPager),
-				)
+				m.Selected = item
+				return m, openBook(m.Selected.title, -1, 0)
 			case key.Matches(msg, _keysShelf.Remove):
-				m.Selected = m.list.Items()[m.list.Index()].(itemShelf)
+				m.Selected = item
 				return m, dialogCmd(dialogMsg{
 					Type:    DialogDefault,
 					Title:   "Delete " + m.Selected.title + "?",
@@ -99,10 +133,33 @@ func (m modelShelf) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return tea.Batch(dialogCmd(dialogMsg{Type: DialogNone}), shelfCmd(shelfMsg{msg: "refresh"}))
 					},
 				})
+			case key.Matches(msg, _keysShelf.Info):
+				m.Selected = item
+				return m, tea.Batch(metadataCmd(m.Selected.title), viewCmd(viewMetadata))
+			case key.Matches(msg, _keysShelf.EPUB):
+				return m, exportCmd(item.title, ExportEPUB)
+			case key.Matches(msg, _keysShelf.MD):
+				return m, exportCmd(item.title, ExportMarkdown)
 			}
 		}
-		if key.Matches(msg, _keysShelf.Import) {
+		switch {
+		case key.Matches(msg, _keysShelf.Import):
 			return m, viewCmd(viewImport)
+		case key.Matches(msg, _keysShelf.Search):
+			return m, tea.Batch(libSearchCmd(), viewCmd(viewLibSearch))
+		case key.Matches(msg, _keysShelf.Stats):
+			return m, tea.Batch(statsCmd(), viewCmd(viewStats))
+		case key.Matches(msg, _keysShelf.Sort, _keysShelf.Group, _keysShelf.Filter):
+			switch {
+			case key.Matches(msg, _keysShelf.Sort):
+				m.mode.sort = (m.mode.sort + 1) % len(_sortNames)
+			case key.Matches(msg, _keysShelf.Group):
+				m.mode.group = (m.mode.group + 1) % len(_groupNames)
+			default:
+				m.mode.filter = (m.mode.filter + 1) % len(_filterNames)
+			}
+			m.mode.save()
+			return m, shelfCmd(shelfMsg{msg: "refresh"})
 		}
 	case tea.WindowSizeMsg:
 		h, v := _docStyle.GetFrameSize()
@@ -110,7 +167,8 @@ func (m modelShelf) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case shelfMsg:
 		switch msg.msg {
 		case "refresh":
-			cmd := m.list.SetItems(getLatestItems())
+			m.list.Title = "Book Shelf · " + m.mode.String()
+			cmd := m.list.SetItems(m.mode.items(dao.GetBooks()))
 			m.list.ResetSelected()
 			return m, cmd
 		}
@@ -118,36 +176,73 @@ func (m modelShelf) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		_typing = m.list.FilterState() == list.Filtering
+	}
 	return m, cmd
 }
 
+// openBook 打开书并跳转到pos行的col字节处，pos<0时跳转到上次阅读的位置
+func openBook(name string, pos int, col int) tea.Cmd {
+	lastPos, err := ProcBook(name)
+	if err != nil {
+		return dialogCmd(dialogMsg{
+			Type:    DialogAlert,
+			Title:   "Open " + name + " failed",
+			Confirm: "OK",
+		})
+	}
+	if pos < 0 {
+		pos = lastPos
+	}
+	title, content, index := GetBookContent(pos)
+	return tea.Batch(
+		pagerCmd(pagerMsg{title: title, content: content, lastPos: pos, col: col, currentIndex: index}),
+		viewCmd(viewPager),
+	)
+}
+
 func (m modelShelf) View() string {
 	return _docStyle.Render(m.list.View())
 }
-func getLatestItems() []list.Item {
-
-	itemShelfs := []list.Item{}
-	books := dao.GetBooks()
-	for _, book := range books {
-		progress := book.LastPos * 100 / (book.Length - 1)
-		if progress > 100 {
-			progress = 100
-		}
-		itemShelfs = append(itemShelfs, itemShelf{title: book.Title, desc: fmt.Sprintf("进度:%d%%", progress)})
+func newItemShelf(book dao.Book) itemShelf {
+	return itemShelf{
+		title:  book.Title,
+		desc:   shelfDesc(book),
+		filter: strings.Join(append([]string{book.Title, book.Author, book.Series}, book.TagList()...), " "),
+	}
+}
+
+// shelfDesc 作者 · 系列 · 进度 · 标签
+func shelfDesc(book dao.Book) string {
+	parts := make([]string, 0, 4)
+	if book.Author != "" {
+		parts = append(parts, book.Author)
+	}
+	if series := FormatSeries(book); series != "" {
+		parts = append(parts, series)
+	}
+	parts = append(parts, fmt.Sprintf("进度:%d%%", BookProgress(book)))
+	if tags := book.TagList(); len(tags) > 0 {
+		parts = append(parts, "#"+strings.Join(tags, " #"))
 	}
-	return itemShelfs
+	return strings.Join(parts, " · ")
 }
 
 func NewShelf() modelShelf {
-	myList := list.New(getLatestItems(), list.NewDefaultDelegate(), 0, 0)
+	mode := loadShelfMode()
+	myList := list.New(mode.items(dao.GetBooks()), list.NewDefaultDelegate(), 0, 0)
 	myList.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{_keysShelf.Select, _keysShelf.Import, _keysShelf.Remove}
+		return []key.Binding{_keysShelf.Select, _keysShelf.Import, _keysShelf.Remove, _keysShelf.Search, _keysShelf.Info,
+			_keysShelf.Sort, _keysShelf.Group, _keysShelf.Filter, _keysShelf.Stats,
+			_keysShelf.EPUB, _keysShelf.MD}
 	}
 
-	myList.Title = "Book Shelf"
+	myList.Title = "Book Shelf · " + mode.String()
 	myList.Styles.Title = titleStyle
 	m := modelShelf{
 		list: myList,
+		mode: mode,
 	}
 
 	return m
@@ -0,0 +1,226 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/list"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// 书架的排序、分组和筛选方式，保存在设置中
+const (
+	_settingShelfSort   = "shelf.sort"
+	_settingShelfGroup  = "shelf.group"
+	_settingShelfFilter = "shelf.filter"
+)
+
+const (
+	sortLastRead = iota
+	sortAdded
+	sortTitle
+	sortAuthor
+	sortProgress
+)
+
+const (
+	groupNone = iota
+	groupStatus
+	groupTag
+)
+
+const (
+	filterAll = iota
+	filterUnfinished
+	filterUnread
+	filterReading
+	filterFinished
+)
+
+var (
+	_sortNames   = []string{"last read", "date added", "title", "author", "progress"}
+	_groupNames  = []string{"", "by status", "by tag"}
+	_filterNames = []string{"", "unfinished", "unread", "reading", "finished"}
+	_statusNames = []string{"Reading", "Unread", "Finished"}
+)
+
+// 阅读状态，按分组显示的顺序排列
+const (
+	statusReading = iota
+	statusUnread
+	statusFinished
+)
+
+// 中文按拼音排序
+var _collator = collate.New(language.Chinese)
+
+type shelfMode struct {
+	sort, group, filter int
+}
+
+// loadShelfMode 读取保存的书架设置
+func loadShelfMode() shelfMode {
+	get := func(key string, count int) int {
+		v, err := strconv.Atoi(dao.GetSetting(key, "0"))
+		if err != nil || v < 0 || v >= count {
+			return 0
+		}
+		return v
+	}
+	return shelfMode{
+		sort:   get(_settingShelfSort, len(_sortNames)),
+		group:  get(_settingShelfGroup, len(_groupNames)),
+		filter: get(_settingShelfFilter, len(_filterNames)),
+	}
+}
+
+func (s shelfMode) save() {
+	dao.SetSetting(_settingShelfSort, strconv.Itoa(s.sort))
+	dao.SetSetting(_settingShelfGroup, strconv.Itoa(s.group))
+	dao.SetSetting(_settingShelfFilter, strconv.Itoa(s.filter))
+}
+
+// String 显示在书架标题后
+func (s shelfMode) String() string {
+	str := _sortNames[s.sort]
+	if s.group != groupNone {
+		str += " · " + _groupNames[s.group]
+	}
+	if s.filter != filterAll {
+		str += " · " + _filterNames[s.filter]
+	}
+	return str
+}
+
+func bookStatus(book dao.Book) int {
+	switch {
+	case book.LastPos == 0:
+		return statusUnread
+	case BookProgress(book) >= 100:
+		return statusFinished
+	}
+	return statusReading
+}
+
+func (s shelfMode) match(book dao.Book) bool {
+	status := bookStatus(book)
+	switch s.filter {
+	case filterUnfinished:
+		return status != statusFinished
+	case filterUnread:
+		return status == statusUnread
+	case filterReading:
+		return status == statusReading
+	case filterFinished:
+		return status == statusFinished
+	}
+	return true
+}
+
+func (s shelfMode) less(a, b dao.Book) bool {
+	switch s.sort {
+	case sortLastRead:
+		if !a.ReadAt.Equal(b.ReadAt) {
+			return a.ReadAt.After(b.ReadAt)
+		}
+	case sortAdded:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+	case sortAuthor:
+		// 没有作者的排在最后
+		if (a.Author == "") != (b.Author == "") {
+			return a.Author != ""
+		}
+		if c := _collator.CompareString(a.Author, b.Author); c != 0 {
+			return c < 0
+		}
+	case sortProgress:
+		if pa, pb := BookProgress(a), BookProgress(b); pa != pb {
+			return pa > pb
+		}
+	}
+	return _collator.CompareString(a.Title, b.Title) < 0
+}
+
+// itemShelfGroup 分组标题
+type itemShelfGroup struct {
+	name  string
+	count int
+}
+
+func (i itemShelfGroup) Title() string       { return "▸ " + i.name }
+func (i itemShelfGroup) Description() string { return fmt.Sprintf("  %d books", i.count) }
+func (i itemShelfGroup) FilterValue() string { return "" }
+
+// items 按当前方式筛选、排序和分组
+func (s shelfMode) items(books []dao.Book) []list.Item {
+	shown := make([]dao.Book, 0, len(books))
+	for _, book := range books {
+		if s.match(book) {
+			shown = append(shown, book)
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool { return s.less(shown[i], shown[j]) })
+
+	items := []list.Item{}
+	if s.group == groupNone {
+		for _, book := range shown {
+			items = append(items, newItemShelf(book))
+		}
+		return items
+	}
+
+	names := make([]string, 0)
+	groups := make(map[string][]dao.Book)
+	add := func(name string, book dao.Book) {
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], book)
+	}
+	for _, book := range shown {
+		switch s.group {
+		case groupStatus:
+			add(_statusNames[bookStatus(book)], book)
+		case groupTag:
+			tags := book.TagList()
+			if len(tags) == 0 {
+				add("", book)
+			}
+			for _, tag := range tags {
+				add(tag, book)
+			}
+		}
+	}
+	if s.group == groupStatus {
+		order := make(map[string]int)
+		for i, name := range _statusNames {
+			order[name] = i
+		}
+		sort.Slice(names, func(i, j int) bool { return order[names[i]] < order[names[j]] })
+	} else {
+		// 没有标签的排在最后
+		sort.Slice(names, func(i, j int) bool {
+			if (names[i] == "") != (names[j] == "") {
+				return names[i] != ""
+			}
+			return _collator.CompareString(names[i], names[j]) < 0
+		})
+	}
+	for _, name := range names {
+		title := name
+		if title == "" {
+			title = "Untagged"
+		}
+		items = append(items, itemShelfGroup{name: title, count: len(groups[name])})
+		for _, book := range groups[name] {
+			items = append(items, newItemShelf(book))
+		}
+	}
+	return items
+}
@@ -0,0 +1,65 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// 双页显示: 终端足够宽时分为左右两栏，像翻开的书一样连续显示两页，每次翻两页
+// pageLines和posMapOffset仍按单页保存，currentPage为左页
+
+const _spreadGutter = 4 // 两页之间至少空出的宽度
+
+// _spreadWidths 可选的自动双页显示的终端宽度，0为关闭
+var _spreadWidths = []int{0, 100, 120, 140, 160, 180, 200, 240}
+
+var pagerSpread bool // 当前是否双页显示
+var spreadGutter int // 两页之间实际空出的宽度
+var procHeight int   // 分页时每页的行数
+
+// spreadOn 宽度为width的终端是否双页显示，滚动模式不分页，不使用双页
+func spreadOn(width int) bool {
+	return !pagerScroll && typo.spreadWidth > 0 && width >= typo.spreadWidth
+}
+
+// pageWidth 宽度为width的终端中每页的宽度
+func pageWidth(width int) int {
+	if spreadOn(width) {
+		return (width - _spreadGutter) / 2
+	}
+	return width
+}
+
+// pageStep 每次翻页的页数
+func pageStep() int {
+	if pagerSpread {
+		return 2
+	}
+	return 1
+}
+
+// spreadStart 第page页所在屏幕的第一页
+func spreadStart(page int) int {
+	return page - (page-1)%pageStep()
+}
+
+// spreadLines 每两页并排为一屏，最后一屏没有右页时补空白
+func spreadLines(lines []string) []string {
+	h := max(procHeight, 1)
+	rows := make([]string, 0, len(lines)/2+h)
+	for start := 0; start < len(lines); start += 2 * h {
+		for r := 0; r < h; r++ {
+			left, right := typo.paintLine("", procWidth), typo.paintLine("", procWidth)
+			if i := start + r; i < len(lines) {
+				left = lines[i]
+			}
+			if i := start + h + r; i < len(lines) {
+				right = lines[i]
+			}
+			gap := max(procWidth-lipgloss.Width(left), 0) + spreadGutter
+			rows = append(rows, left+typo.paint(strings.Repeat(" ", gap))+right)
+		}
+	}
+	return rows
+}
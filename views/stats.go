@@ -0,0 +1,309 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	_statsDays     = 14 // 按天显示的天数
+	_statsWeeks    = 8  // 按周显示的周数
+	_statsBarWidth = 30
+	_statsAvgDays  = 30              // 计算每天页数的天数
+	_minSpeedTime  = 5 * time.Minute // 单本书阅读时间不足时使用总体速度
+)
+
+var (
+	_statsBarStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	_statsHeadingStyle = lipgloss.NewStyle().Bold(true)
+)
+
+type statsMsg struct{}
+
+func statsCmd() tea.Cmd {
+	return func() tea.Msg {
+		return statsMsg{}
+	}
+}
+
+var _keysStats = struct {
+	Back key.Binding
+}{
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q", "S"),
+		key.WithHelp("q", "back"),
+	),
+}
+
+// readingTotal 一段时间内的阅读量
+type readingTotal struct {
+	time  time.Duration
+	lines int
+	pages int
+}
+
+func (t *readingTotal) add(s dao.Session) {
+	t.time += s.Duration()
+	t.lines += s.Lines
+	t.pages += s.Pages
+}
+
+// speed 每秒读过的行数
+func (t readingTotal) speed() float64 {
+	if t.time <= 0 {
+		return 0
+	}
+	return float64(t.lines) / t.time.Seconds()
+}
+
+// estimateSpeed 单本书阅读时间不足时使用总体速度
+func estimateSpeed(book readingTotal, all readingTotal) float64 {
+	if speed := book.speed(); book.time >= _minSpeedTime && speed > 0 {
+		return speed
+	}
+	return all.speed()
+}
+
+// readingSpeed 按所有阅读记录估计书的阅读速度，没有记录时为0
+func readingSpeed(bookID uint) float64 {
+	var book, all readingTotal
+	for _, s := range dao.GetSessions(time.Time{}) {
+		all.add(s)
+		if s.BookID == bookID {
+			book.add(s)
+		}
+	}
+	return estimateSpeed(book, all)
+}
+
+// startOfDay 当天零点，统一为本地时区以便作为map的key
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek 每周从周一开始
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// formatDuration 显示为1h05m、12m
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		if d <= 0 {
+			return "0m"
+		}
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func statsBar(value float64, maxValue float64) string {
+	if maxValue <= 0 || value <= 0 {
+		return strings.Repeat(" ", _statsBarWidth)
+	}
+	n := max(int(value/maxValue*_statsBarWidth), 1)
+	return _statsBarStyle.Render(strings.Repeat("█", n)) + strings.Repeat(" ", _statsBarWidth-n)
+}
+
+// streak 到今天(今天还没读时到昨天)为止连续阅读的天数，以及最长连续天数
+func streak(days map[time.Time]bool, today time.Time) (current int, best int) {
+	sorted := make([]time.Time, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	run := 0
+	for i, day := range sorted {
+		if i > 0 && startOfDay(sorted[i-1].AddDate(0, 0, 1)).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		best = max(best, run)
+	}
+	day := today
+	if !days[day] {
+		day = startOfDay(day.AddDate(0, 0, -1))
+	}
+	for days[day] {
+		current++
+		day = startOfDay(day.AddDate(0, 0, -1))
+	}
+	return
+}
+
+// renderStats 统计所有阅读记录
+func renderStats(sessions []dao.Session, books []dao.Book, now time.Time) string {
+	if len(sessions) == 0 {
+		return "No reading sessions yet. Statistics appear after you turn a few pages."
+	}
+	today := startOfDay(now)
+	thisWeek := startOfWeek(now)
+	var all, todayTotal, weekTotal, recent readingTotal
+	daily := make(map[time.Time]*readingTotal)
+	weekly := make(map[time.Time]*readingTotal)
+	perBook := make(map[uint]*readingTotal)
+	readDays := make(map[time.Time]bool)
+	for _, s := range sessions {
+		day, week := startOfDay(s.StartAt), startOfWeek(s.StartAt)
+		all.add(s)
+		if daily[day] == nil {
+			daily[day] = &readingTotal{}
+		}
+		daily[day].add(s)
+		if weekly[week] == nil {
+			weekly[week] = &readingTotal{}
+		}
+		weekly[week].add(s)
+		if perBook[s.BookID] == nil {
+			perBook[s.BookID] = &readingTotal{}
+		}
+		perBook[s.BookID].add(s)
+		if s.Pages > 0 {
+			readDays[day] = true
+		}
+		if day.Equal(today) {
+			todayTotal.add(s)
+		}
+		if !week.Before(thisWeek) {
+			weekTotal.add(s)
+		}
+		if !day.Before(today.AddDate(0, 0, -_statsAvgDays+1)) {
+			recent.add(s)
+		}
+	}
+
+	var sb strings.Builder
+	current, best := streak(readDays, today)
+	fmt.Fprintf(&sb, "%-12s %8s  %5d pages\n", "Today", formatDuration(todayTotal.time), todayTotal.pages)
+	fmt.Fprintf(&sb, "%-12s %8s  %5d pages\n", "This week", formatDuration(weekTotal.time), weekTotal.pages)
+	fmt.Fprintf(&sb, "%-12s %8s  %5d pages\n", "All time", formatDuration(all.time), all.pages)
+	fmt.Fprintf(&sb, "%-12s %8.1f pages (last %d days)\n", "Pages/day", float64(recent.pages)/_statsAvgDays, _statsAvgDays)
+	fmt.Fprintf(&sb, "%-12s %8d days (best %d)\n", "Streak", current, best)
+	if speed := all.speed(); speed > 0 {
+		fmt.Fprintf(&sb, "%-12s %8.1f lines/min\n", "Speed", speed*60)
+	}
+
+	// 每天
+	sb.WriteString("\n" + _statsHeadingStyle.Render(fmt.Sprintf("Last %d days", _statsDays)) + "\n")
+	maxDay := 0.0
+	for i := 0; i < _statsDays; i++ {
+		if t := daily[today.AddDate(0, 0, -i)]; t != nil {
+			maxDay = max(maxDay, t.time.Seconds())
+		}
+	}
+	for i := _statsDays - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i)
+		t := daily[day]
+		if t == nil {
+			t = &readingTotal{}
+		}
+		fmt.Fprintf(&sb, "%s  %s %7s %5dp\n", day.Format("01-02 Mon"), statsBar(t.time.Seconds(), maxDay), formatDuration(t.time), t.pages)
+	}
+
+	// 每周
+	sb.WriteString("\n" + _statsHeadingStyle.Render(fmt.Sprintf("Last %d weeks", _statsWeeks)) + "\n")
+	maxWeek := 0.0
+	for i := 0; i < _statsWeeks; i++ {
+		if t := weekly[thisWeek.AddDate(0, 0, -7*i)]; t != nil {
+			maxWeek = max(maxWeek, t.time.Seconds())
+		}
+	}
+	for i := _statsWeeks - 1; i >= 0; i-- {
+		week := thisWeek.AddDate(0, 0, -7*i)
+		t := weekly[week]
+		if t == nil {
+			t = &readingTotal{}
+		}
+		fmt.Fprintf(&sb, "%s      %s %7s %5dp\n", week.Format("01-02"), statsBar(t.time.Seconds(), maxWeek), formatDuration(t.time), t.pages)
+	}
+
+	// 每本书，按阅读时间排列
+	sb.WriteString("\n" + _statsHeadingStyle.Render("Books") + "\n")
+	sort.SliceStable(books, func(i, j int) bool {
+		var a, b time.Duration
+		if t := perBook[books[i].ID]; t != nil {
+			a = t.time
+		}
+		if t := perBook[books[j].ID]; t != nil {
+			b = t.time
+		}
+		return a > b
+	})
+	fmt.Fprintf(&sb, "%s  %8s  %10s  %s\n", runewidth.FillRight("Title", 30), "Time", "Speed", "To finish")
+	for _, book := range books {
+		t := perBook[book.ID]
+		if t == nil {
+			continue
+		}
+		speed := estimateSpeed(*t, all)
+		left := "-"
+		remaining := book.Length - 1 - book.LastPos
+		switch {
+		case remaining <= 0:
+			left = "done"
+		case speed > 0:
+			left = "~" + formatDuration(time.Duration(float64(remaining)/speed)*time.Second)
+		}
+		rate := "-"
+		if t.speed() > 0 {
+			rate = fmt.Sprintf("%.1f l/m", t.speed()*60)
+		}
+		title := runewidth.FillRight(runewidth.Truncate(book.Title, 30, "…"), 30)
+		fmt.Fprintf(&sb, "%s  %8s  %10s  %s\n", title, formatDuration(t.time), rate, left)
+	}
+	return sb.String()
+}
+
+type modelStats struct {
+	viewport viewport.Model
+}
+
+func (m modelStats) Init() tea.Cmd {
+	return nil
+}
+
+func (m modelStats) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := _docStyle.GetFrameSize()
+		m.viewport.Width = msg.Width - h
+		m.viewport.Height = msg.Height - v - 2
+		return m, nil
+	case statsMsg:
+		m.viewport.SetContent(renderStats(dao.GetSessions(time.Time{}), dao.GetBooks(), time.Now()))
+		m.viewport.GotoTop()
+		return m, nil
+	case tea.KeyMsg:
+		if key.Matches(msg, _keysStats.Back) {
+			return m, viewCmd(viewShelf)
+		}
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m modelStats) View() string {
+	return _docStyle.Render(titleStyle.Render("Statistics") + "\n\n" + m.viewport.View())
+}
+
+func NewStats() modelStats {
+	return modelStats{viewport: viewport.New(0, 0)}
+}
@@ -0,0 +1,123 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// 页脚的状态栏，每一项可以在排版设置中单独关闭
+const (
+	_settingStatusPage     = "pager.status.page"
+	_settingStatusChapter  = "pager.status.chapter"
+	_settingStatusPercent  = "pager.status.percent"
+	_settingStatusBar      = "pager.status.bar"
+	_settingStatusTimeLeft = "pager.status.timeLeft"
+)
+
+const (
+	_statusBarMin = 10
+	_statusBarMax = 40
+)
+
+var (
+	_statusStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	_statusDoneStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	_statusLeftStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+)
+
+type statusItems struct {
+	page     bool // 本章第几页
+	chapter  bool // 第几章
+	percent  bool // 全书进度
+	bar      bool
+	timeLeft bool // 按阅读速度估计的剩余时间
+}
+
+var status = statusItems{page: true, chapter: true, percent: true, bar: true, timeLeft: true}
+
+var bookSpeed float64 // 当前书的阅读速度，每秒的行数
+
+func loadStatusItems() statusItems {
+	getBool := func(key string) bool {
+		v, err := strconv.ParseBool(dao.GetSetting(key, "true"))
+		return err != nil || v
+	}
+	return statusItems{
+		page:     getBool(_settingStatusPage),
+		chapter:  getBool(_settingStatusChapter),
+		percent:  getBool(_settingStatusPercent),
+		bar:      getBool(_settingStatusBar),
+		timeLeft: getBool(_settingStatusTimeLeft),
+	}
+}
+
+func (s statusItems) save() {
+	dao.SetSetting(_settingStatusPage, strconv.FormatBool(s.page))
+	dao.SetSetting(_settingStatusChapter, strconv.FormatBool(s.chapter))
+	dao.SetSetting(_settingStatusPercent, strconv.FormatBool(s.percent))
+	dao.SetSetting(_settingStatusBar, strconv.FormatBool(s.bar))
+	dao.SetSetting(_settingStatusTimeLeft, strconv.FormatBool(s.timeLeft))
+}
+
+// statusView 页脚上方的状态栏，所有项都关闭时为空行
+func (m modelPager) statusView() string {
+	parts := make([]string, 0, 4)
+	if status.page {
+		page := strconv.Itoa(currentPage)
+		if pagerSpread && currentPage < pageTotal {
+			page += "-" + strconv.Itoa(currentPage+1)
+		}
+		parts = append(parts, _statusStyle.Render(fmt.Sprintf("page %s/%d", page, pageTotal)))
+	}
+	if status.chapter && m.currentIndex >= 0 {
+		parts = append(parts, _statusStyle.Render(fmt.Sprintf("ch. %d/%d", m.currentIndex+1, len(bookDirs))))
+	}
+	progress := BookProgress(dao.Book{LastPos: bookPos, Length: len(bookAll)})
+	if status.percent {
+		parts = append(parts, _statusStyle.Render(strconv.Itoa(progress)+"%"))
+	}
+	left := ""
+	if status.timeLeft {
+		left = timeLeft(len(bookAll)-1-bookPos, bookSpeed)
+	}
+	if status.bar {
+		// 进度条使用剩余的宽度，每项之间空两格
+		used := 1 + lipgloss.Width(strings.Join(parts, "")) + 2*len(parts)
+		if left != "" {
+			used += lipgloss.Width(left) + 2
+		}
+		if width := m.viewport.Width - used; width >= _statusBarMin {
+			parts = append(parts, progressBar(progress, min(width, _statusBarMax)))
+		}
+	}
+	if left != "" {
+		parts = append(parts, _statusStyle.Render(left))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, "  ")
+}
+
+// progressBar 宽度为width的进度条
+func progressBar(percent int, width int) string {
+	done := width * percent / 100
+	return _statusDoneStyle.Render(strings.Repeat("━", done)) + _statusLeftStyle.Render(strings.Repeat("─", width-done))
+}
+
+// timeLeft 剩余remaining行按speed估计的阅读时间，没有阅读记录时为空
+func timeLeft(remaining int, speed float64) string {
+	switch {
+	case remaining <= 0:
+		return "done"
+	case speed <= 0:
+		return ""
+	}
+	return "~" + formatDuration(time.Duration(float64(remaining)/speed)*time.Second) + " left"
+}
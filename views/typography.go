@@ -0,0 +1,283 @@
+package views
+
+import (
+	"strconv"
+	"strings"
+
+	"go-reader/dao"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// 阅读界面的排版设置
+const (
+	_settingTheme       = "pager.theme"
+	_settingMaxWidth    = "pager.maxWidth"
+	_settingMargin      = "pager.margin"
+	_settingParaSpacing = "pager.paraSpacing"
+	_settingIndent      = "pager.indent"
+	_settingHyphenate   = "pager.hyphenate"
+	_settingSpreadWidth = "pager.spreadWidth"
+)
+
+const (
+	_maxMargin    = 10
+	_indentHan    = "　　" // 中文首行缩进两个全角空格
+	_indentLatin  = "  "
+	_leadingSpace = " \t　"
+)
+
+// _maxWidths 可选的正文最大宽度，0为不限制
+var _maxWidths = []int{0, 40, 50, 60, 70, 80, 90, 100, 120, 140, 160}
+
+// theme 阅读时的配色，颜色为空时使用终端的颜色
+type theme struct {
+	name   string
+	fg, bg string
+}
+
+var _themes = []theme{
+	{name: "terminal"},
+	{name: "day", fg: "#1f1f1f", bg: "#fafafa"},
+	{name: "night", fg: "#c8c8c8", bg: "#1e1e1e"},
+	{name: "sepia", fg: "#5b4636", bg: "#f4ecd8"},
+	{name: "high-contrast", fg: "#ffffff", bg: "#000000"},
+}
+
+type typography struct {
+	theme       int
+	maxWidth    int // 正文最大宽度，0为不限制
+	margin      int // 左右边距
+	paraSpacing bool
+	indent      bool
+	hyphenate   bool // 西文单词在行尾断开时加连字符
+	spreadWidth int  // 终端宽度不小于此值时双页显示，0为关闭
+}
+
+const _spreadWidthDefault = 160
+
+var typo = typography{margin: 1, spreadWidth: _spreadWidthDefault}
+
+func loadTypography() typography {
+	getInt := func(key string, def int, lo int, hi int) int {
+		v, err := strconv.Atoi(dao.GetSetting(key, ""))
+		if err != nil || v < lo || v > hi {
+			return def
+		}
+		return v
+	}
+	getBool := func(key string) bool {
+		v, _ := strconv.ParseBool(dao.GetSetting(key, "false"))
+		return v
+	}
+	return typography{
+		theme:       getInt(_settingTheme, 0, 0, len(_themes)-1),
+		maxWidth:    getInt(_settingMaxWidth, 0, 0, _maxWidths[len(_maxWidths)-1]),
+		margin:      getInt(_settingMargin, 1, 0, _maxMargin),
+		paraSpacing: getBool(_settingParaSpacing),
+		indent:      getBool(_settingIndent),
+		hyphenate:   getBool(_settingHyphenate),
+		spreadWidth: getInt(_settingSpreadWidth, _spreadWidthDefault, 0, _spreadWidths[len(_spreadWidths)-1]),
+	}
+}
+
+func (t typography) save() {
+	dao.SetSetting(_settingTheme, strconv.Itoa(t.theme))
+	dao.SetSetting(_settingMaxWidth, strconv.Itoa(t.maxWidth))
+	dao.SetSetting(_settingMargin, strconv.Itoa(t.margin))
+	dao.SetSetting(_settingParaSpacing, strconv.FormatBool(t.paraSpacing))
+	dao.SetSetting(_settingIndent, strconv.FormatBool(t.indent))
+	dao.SetSetting(_settingHyphenate, strconv.FormatBool(t.hyphenate))
+	dao.SetSetting(_settingSpreadWidth, strconv.Itoa(t.spreadWidth))
+}
+
+// textWidth 宽度为width的终端中正文的宽度
+func (t typography) textWidth(width int) int {
+	w := width - 2*t.margin
+	if t.maxWidth > 0 {
+		w = min(w, t.maxWidth)
+	}
+	return max(w, 2)
+}
+
+// leftPad 正文居中时左边的空白
+func (t typography) leftPad(width int) int {
+	return max((width-t.textWidth(width))/2, 0)
+}
+
+// style 当前配色的样式，使用终端颜色时ok为false
+func (t typography) style() (style lipgloss.Style, ok bool) {
+	th := _themes[t.theme]
+	if th.fg == "" && th.bg == "" {
+		return lipgloss.NewStyle(), false
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(th.fg)).Background(lipgloss.Color(th.bg)), true
+}
+
+// paint 用当前配色显示s
+func (t typography) paint(s string) string {
+	if style, ok := t.style(); ok {
+		return style.Render(s)
+	}
+	return s
+}
+
+// _wideSpaces 视口按字节计算空白的宽度，多字节的空白会使整行宽度的行被错误地折行，显示时换成空格
+var _wideSpaces = strings.NewReplacer("\u3000", "  ", "\u00a0", " ")
+
+// paintLine 居中显示一行，左右的空白也使用配色
+func (t typography) paintLine(line string, width int) string {
+	line = _wideSpaces.Replace(line)
+	pad := t.leftPad(width)
+	right := max(width-pad-lipgloss.Width(line), 0)
+	if _, ok := t.style(); !ok {
+		return strings.Repeat(" ", pad) + line
+	}
+	return t.paint(strings.Repeat(" ", pad)) + line + t.paint(strings.Repeat(" ", right))
+}
+
+// paragraphIndent 开启首行缩进时，返回去掉行首空白的字节数和缩进
+func (t typography) paragraphIndent(line string) (skip int, indent string) {
+	if !t.indent {
+		return 0, ""
+	}
+	trimmed := strings.TrimLeft(line, _leadingSpace)
+	if trimmed == "" {
+		return 0, ""
+	}
+	indent = _indentLatin
+	if hasHan(trimmed) {
+		indent = _indentHan
+	}
+	return len(line) - len(trimmed), indent
+}
+
+// pagerOption 阅读界面中可调整的设置，change后立即生效
+type pagerOption struct {
+	name   string
+	value  func() string
+	change func(delta int)
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// cycle 在n个值中循环移动
+func cycle(i int, delta int, n int) int {
+	return ((i+delta)%n + n) % n
+}
+
+// cycleValue 在升序的values中从v所在的位置循环移动
+func cycleValue(values []int, v int, delta int) int {
+	i := 0
+	for j, w := range values {
+		if w <= v {
+			i = j
+		}
+	}
+	return values[cycle(i, delta, len(values))]
+}
+
+var _pagerOptions = []pagerOption{
+	{
+		name:   "Theme",
+		value:  func() string { return _themes[typo.theme].name },
+		change: func(delta int) { typo.theme = cycle(typo.theme, delta, len(_themes)) },
+	},
+	{
+		name: "Max width",
+		value: func() string {
+			if typo.maxWidth == 0 {
+				return "unlimited"
+			}
+			return strconv.Itoa(typo.maxWidth)
+		},
+		change: func(delta int) { typo.maxWidth = cycleValue(_maxWidths, typo.maxWidth, delta) },
+	},
+	{
+		name:   "Margin",
+		value:  func() string { return strconv.Itoa(typo.margin) },
+		change: func(delta int) { typo.margin = min(max(typo.margin+delta, 0), _maxMargin) },
+	},
+	{
+		name:   "Paragraph spacing",
+		value:  func() string { return onOff(typo.paraSpacing) },
+		change: func(int) { typo.paraSpacing = !typo.paraSpacing },
+	},
+	{
+		name:   "First-line indent",
+		value:  func() string { return onOff(typo.indent) },
+		change: func(int) { typo.indent = !typo.indent },
+	},
+	{
+		name:   "Hyphenation",
+		value:  func() string { return onOff(typo.hyphenate) },
+		change: func(int) { typo.hyphenate = !typo.hyphenate },
+	},
+	{
+		name: "Two-page spread",
+		value: func() string {
+			if typo.spreadWidth == 0 {
+				return "off"
+			}
+			return "width >= " + strconv.Itoa(typo.spreadWidth)
+		},
+		change: func(delta int) { typo.spreadWidth = cycleValue(_spreadWidths, typo.spreadWidth, delta) },
+	},
+	{
+		name:   "Status: page",
+		value:  func() string { return onOff(status.page) },
+		change: func(int) { status.page = !status.page },
+	},
+	{
+		name:   "Status: chapter",
+		value:  func() string { return onOff(status.chapter) },
+		change: func(int) { status.chapter = !status.chapter },
+	},
+	{
+		name:   "Status: percent",
+		value:  func() string { return onOff(status.percent) },
+		change: func(int) { status.percent = !status.percent },
+	},
+	{
+		name:   "Status: progress bar",
+		value:  func() string { return onOff(status.bar) },
+		change: func(int) { status.bar = !status.bar },
+	},
+	{
+		name:   "Status: time left",
+		value:  func() string { return onOff(status.timeLeft) },
+		change: func(int) { status.timeLeft = !status.timeLeft },
+	},
+}
+
+var _keysPagerOptions = struct {
+	Prev   key.Binding
+	Next   key.Binding
+	Less   key.Binding
+	More   key.Binding
+	Close  key.Binding
+	Toggle key.Binding
+}{
+	Prev:   key.NewBinding(key.WithKeys("up", "k", "shift+tab")),
+	Next:   key.NewBinding(key.WithKeys("down", "j", "tab"), key.WithHelp("↑/↓", "option")),
+	Less:   key.NewBinding(key.WithKeys("left", "h")),
+	More:   key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("←/→", "change")),
+	Close:  key.NewBinding(key.WithKeys("esc", "enter", "o", "q"), key.WithHelp("esc", "done")),
+	Toggle: key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "options")),
+}
+
+var _optionStyle = lipgloss.NewStyle().Bold(true)
+
+// optionView 页脚中显示的当前设置
+func optionView(i int) string {
+	opt := _pagerOptions[i]
+	return runewidth.FillRight(opt.name+":", 20) + _optionStyle.Render("‹ "+opt.value()+" ›") +
+		subTitleStyle.Render(strconv.Itoa(i+1)+"/"+strconv.Itoa(len(_pagerOptions)))
+}
@@ -33,14 +33,24 @@ const (
 	viewImport
 	viewPager
 	viewDirList
+	viewChapterRule
+	viewBookmark
+	viewSearch
+	viewLibSearch
+	viewMetadata
+	viewStats
+	viewAnnotation
 )
 
 var winwidth, winheight int
 var titleStyle = lipgloss.NewStyle().Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230")).Padding(0, 1)
 var subTitleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Padding(0, 2)
 
-var _winTitle = []string{"BookShelf", "Import Book", "Reading", "Directory List"}
+var _winTitle = []string{"BookShelf", "Import Book", "Reading", "Directory List", "Chapter Rule", "Bookmarks", "Search", "Library Search", "Book Info", "Statistics", "Annotations"}
 var _curView = viewShelf
+
+// 输入框或过滤框获得焦点时为true，此时x作为普通字符输入
+var _typing bool
 var _keysViews = keyMapViews{
 	ForceQuit: key.NewBinding(key.WithKeys("x", "ctrl+c")),
 }
@@ -60,7 +70,10 @@ func (v modelViews) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if key.Matches(msg, _keysViews.ForceQuit) {
+		if key.Matches(msg, _keysViews.ForceQuit) && !(_typing && msg.Type == tea.KeyRunes) {
+			// 退出前保存阅读位置和阅读记录，防抖中的保存在退出后不会执行
+			updateBookPosDebounce.Flush()
+			endSession()
 			return v, tea.Quit
 		}
 		// tea.KeyMsg 只执行当前step的update,当有dialog时只执行dialog的update
@@ -98,8 +111,15 @@ func NewViews() {
 	imp := NewImport()
 	pager := NewPager()
 	dirList := NewDirList()
+	rule := NewRule()
+	bookmark := NewBookmark()
+	search := NewSearch()
+	libSearch := NewLibSearch()
+	metadata := NewMetadata()
+	stats := NewStats()
+	annotation := NewAnnotation()
 
-	models := []tea.Model{shelf, imp, pager, dirList}
+	models := []tea.Model{shelf, imp, pager, dirList, rule, bookmark, search, libSearch, metadata, stats, annotation}
 	m := modelViews{
 		models: models,
 		dialog: dialog,
@@ -0,0 +1,183 @@
+package views
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 简繁转换的方向，为空时不转换
+const (
+	ConvertS2T = "s2t" // 简体转繁体
+	ConvertT2S = "t2s" // 繁体转简体
+)
+
+// _converts 按c依次切换
+var _converts = []string{"", ConvertS2T, ConvertT2S}
+
+// nextConvert 切换到下一个转换方向
+func nextConvert(convert string) string {
+	for i, c := range _converts {
+		if c == convert {
+			return _converts[(i+1)%len(_converts)]
+		}
+	}
+	return ""
+}
+
+// ConvertLabel 显示用的转换方向
+func ConvertLabel(convert string) string {
+	switch convert {
+	case ConvertS2T:
+		return "简→繁"
+	case ConvertT2S:
+		return "繁→简"
+	}
+	return ""
+}
+
+// IsConvert 是否为支持的转换方向
+func IsConvert(convert string) bool {
+	return convert == ConvertS2T || convert == ConvertT2S
+}
+
+// zhConverter 先按词组最长匹配，再逐字转换。转换前后的字节数相同，
+// 因此标注、搜索结果等按字节记录的位置在转换后仍然可用
+type zhConverter struct {
+	chars   map[rune]rune
+	phrases map[string]string
+	starts  map[rune]bool // 词组的第一个字
+	maxLen  int           // 最长词组的字数
+}
+
+var (
+	_zhOnce       sync.Once
+	_zhConverters map[string]*zhConverter
+	_zhFold       map[rune]rune // 搜索时统一转为简体
+)
+
+func loadZhDict() {
+	s2t := make(map[rune]rune)
+	t2s := make(map[rune]rune)
+	pairs := []rune(_zhChars)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s, t := pairs[i], pairs[i+1]
+		if utf8.RuneLen(s) != utf8.RuneLen(t) {
+			continue
+		}
+		s2t[s] = t
+		if _, ok := t2s[t]; !ok {
+			t2s[t] = s
+		}
+	}
+	pairs = []rune(_zhCharsT2S)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if t, s := pairs[i], pairs[i+1]; utf8.RuneLen(s) == utf8.RuneLen(t) {
+			t2s[t] = s
+		}
+	}
+	_zhConverters = map[string]*zhConverter{
+		ConvertS2T: newZhConverter(s2t, _zhPhrasesS2T),
+		ConvertT2S: newZhConverter(t2s, _zhPhrasesT2S),
+	}
+	_zhFold = t2s
+}
+
+func newZhConverter(chars map[rune]rune, phrases string) *zhConverter {
+	c := &zhConverter{chars: chars, phrases: make(map[string]string), starts: make(map[rune]bool)}
+	for _, p := range strings.Fields(phrases) {
+		from, to, ok := strings.Cut(p, ":")
+		if !ok || len(from) != len(to) {
+			continue
+		}
+		c.phrases[from] = to
+		first, _ := utf8.DecodeRuneInString(from)
+		c.starts[first] = true
+		c.maxLen = max(c.maxLen, utf8.RuneCountInString(from))
+	}
+	return c
+}
+
+func (c *zhConverter) convert(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if c.starts[r] {
+			if end, to := c.matchPhrase(s, i); end > 0 {
+				sb.WriteString(to)
+				i = end
+				continue
+			}
+		}
+		if t, ok := c.chars[r]; ok {
+			sb.WriteRune(t)
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// matchPhrase 从start开始最长的词组，返回词组结束的位置和转换后的词组，没有时end为0
+func (c *zhConverter) matchPhrase(s string, start int) (end int, to string) {
+	// ends[n]为start开始n个字的结束位置
+	ends := make([]int, 0, c.maxLen+1)
+	ends = append(ends, start)
+	for j := start; len(ends) <= c.maxLen && j < len(s); {
+		_, size := utf8.DecodeRuneInString(s[j:])
+		j += size
+		ends = append(ends, j)
+	}
+	for n := len(ends) - 1; n >= 2; n-- {
+		if to, ok := c.phrases[s[start:ends[n]]]; ok {
+			return ends[n], to
+		}
+	}
+	return 0, ""
+}
+
+// needConvert 是否含有非ASCII字符，纯西文不需要转换
+func needConvert(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert 按convert转换简繁，convert为空时原样返回
+func Convert(s string, convert string) string {
+	if convert == "" || !needConvert(s) {
+		return s
+	}
+	_zhOnce.Do(loadZhDict)
+	c, ok := _zhConverters[convert]
+	if !ok {
+		return s
+	}
+	return c.convert(s)
+}
+
+// foldScript 逐字转为简体，搜索时用来同时匹配简体和繁体。
+// 字节位置不变，无效的UTF-8字节原样保留
+func foldScript(s string) string {
+	if !needConvert(s) {
+		return s
+	}
+	_zhOnce.Do(loadZhDict)
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if f, ok := _zhFold[r]; ok {
+			sb.WriteRune(f)
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package views
+
+import (
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		in      string
+		convert string
+		want    string
+	}{
+		{"头发很长", ConvertS2T, "頭髮很長"},
+		{"开头发现", ConvertS2T, "開頭發現"},
+		{"干净的衣服", ConvertS2T, "乾淨的衣服"},
+		{"干部在干什么", ConvertS2T, "幹部在幹什麼"},
+		{"皇后在后面", ConvertS2T, "皇后在後面"},
+		{"頭髮很長", ConvertT2S, "头发很长"},
+		{"乾隆皇帝，乾淨", ConvertT2S, "乾隆皇帝，干净"},
+		{"著作等身", ConvertT2S, "著作等身"},
+		{"plain ascii", ConvertS2T, "plain ascii"},
+		{"头发", "", "头发"},
+		{"头发", "unknown", "头发"},
+		{"\xff头发\xfe", ConvertS2T, "\xff頭髮\xfe"},
+	}
+	for _, tt := range tests {
+		if got := Convert(tt.in, tt.convert); got != tt.want {
+			t.Errorf("Convert(%q, %q) = %q, want %q", tt.in, tt.convert, got, tt.want)
+		}
+		if got := Convert(tt.in, tt.convert); len(got) != len(tt.in) {
+			t.Errorf("Convert(%q, %q) changed the byte length: %d -> %d", tt.in, tt.convert, len(tt.in), len(got))
+		}
+	}
+}
+
+func TestFoldScript(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"頭髮", "头发"},
+		{"头发", "头发"},
+		{"abc", "abc"},
+		{"\xff\xff\xff中文abc", "\xff\xff\xff中文abc"},
+		{"開\xc0始", "开\xc0始"},
+		{"結束\xe4\xb8", "结束\xe4\xb8"},
+	}
+	for _, tt := range tests {
+		got := foldScript(tt.in)
+		if got != tt.want {
+			t.Errorf("foldScript(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if len(got) != len(tt.in) {
+			t.Errorf("foldScript(%q) changed the byte length: %d -> %d", tt.in, len(tt.in), len(got))
+		}
+	}
+}
+
+// 无效的UTF-8字节不能使搜索结果的位置超出原文
+func TestSearchInvalidUTF8(t *testing.T) {
+	lines := []string{"\xff\xff\xff中文abc", "開始\xfe结束"}
+	tests := []struct {
+		query string
+		want  []searchHit
+	}{
+		{"abc", []searchHit{{line: 0, start: 9, end: 12}}},
+		{"开始", []searchHit{{line: 1, start: 0, end: 6}}},
+		{"結束", []searchHit{{line: 1, start: 7, end: 13}}},
+	}
+	for _, tt := range tests {
+		re, err := compileSearch(tt.query, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hits := findHits(lines, re, 10)
+		if len(hits) != len(tt.want) {
+			t.Fatalf("findHits(%q) = %v, want %v", tt.query, hits, tt.want)
+		}
+		for i, hit := range hits {
+			if hit != tt.want[i] {
+				t.Errorf("findHits(%q)[%d] = %v, want %v", tt.query, i, hit, tt.want[i])
+			}
+			hitSnippet(lines[hit.line], hit.start, hit.end)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package views
+
+// 简繁转换的字典，每个字和词组转换前后的UTF-8长度相同
+
+// _zhChars 简体到繁体的逐字对照，每两个字为一组。一个简体对应多个繁体时取最常用的，其余由词组处理
+const _zhChars = "" +
+	"计計订訂讣訃认認讥譏讦訐讧訌讨討让讓讪訕讫訖训訓议議讯訊记記讲講讳諱讴謳讵詎讶訝讷訥许許讹訛论論讼訟讽諷设設访訪诀訣证證诂詁诃訶" +
+	"评評诅詛识識诈詐诉訴诊診诋詆词詞诎詘诏詔译譯诒詒诓誆诔誄试試诗詩诘詰诙詼诚誠诛誅话話诞誕诟詬诠詮诡詭询詢诣詣诤諍该該详詳诧詫诨諢" +
+	"诩詡诫誡诬誣语語诮誚误誤诰誥诱誘诲誨诳誑说說诵誦请請诸諸诹諏诺諾读讀诼諑诽誹课課诿諉谀諛谁誰谂諗调調谄諂谅諒谆諄谇誶谈談谊誼谋謀" +
+	"谌諶谍諜谎謊谏諫谐諧谑謔谒謁谓謂谔諤谕諭谖諼谗讒谘諮谙諳谚諺谛諦谜謎谝諞谟謨谠讜谡謖谢謝谣謠谤謗谥諡谦謙谧謐谨謹谩謾谪謫谬謬谭譚" +
+	"谮譖谯譙谰讕谱譜谲譎谳讞谴譴谵譫谶讖针針钉釘钊釗钌釕钍釷钎釺钏釧钐釤钒釩钓釣钔鍆钕釹钗釵钙鈣钛鈦钜鉅钝鈍钞鈔钟鐘钠鈉钡鋇钢鋼钣鈑" +
+	"钤鈐钥鑰钦欽钧鈞钨鎢钩鉤钪鈧钫鈁钬鈥钮鈕钯鈀钰鈺钱錢钲鉦钳鉗钴鈷钵缽钶鈳钸鈽钹鈸钺鉞钻鑽钼鉬钽鉭钾鉀钿鈿铀鈾铁鐵铂鉑铃鈴铄鑠铅鉛" +
+	"铆鉚铈鈰铉鉉铊鉈铋鉍铌鈮铍鈹铎鐸铐銬铑銠铒鉺铕銪铖鋮铗鋏铙鐃铛鐺铜銅铝鋁铟銦铠鎧铡鍘铢銖铣銑铤鋌铥銩铧鏵铨銓铩鎩铪鉿铫銚铬鉻铭銘" +
+	"铮錚铯銫铰鉸铱銥铲鏟铳銃铵銨银銀铷銣铸鑄铺鋪铼錸铽鋱链鏈铿鏗销銷锁鎖锂鋰锃鋥锄鋤锅鍋锆鋯锇鋨锈鏽锉銼锋鋒锌鋅锏鐧锐銳锑銻锒鋃锔鋦" +
+	"锕錒锗鍺错錯锚錨锛錛锞錁锟錕锡錫锢錮锣鑼锤錘锥錐锦錦锨鍁锩錈锬錟锭錠键鍵锯鋸锰錳锱錙锲鍥锴鍇锵鏘锶鍶锷鍔锸鍤锹鍬锺鍾锻鍛锼鎪镀鍍" +
+	"镁鎂镂鏤镆鏌镇鎮镉鎘镊鑷镌鐫镍鎳镏鎦镐鎬镑鎊镒鎰镓鎵镔鑌镖鏢镗鏜镘鏝镛鏞镜鏡镝鏑镞鏃镡鐔镣鐐镤鏷镦鐓镧鑭镨鐠镪鏹镫鐙镬鑊镭鐳镯鐲" +
+	"镰鐮镱鐿镲鑔镳鑣镶鑲纠糾纡紆红紅纣紂纤纖纥紇约約级級纨紈纩纊纪紀纫紉纬緯纭紜纯純纰紕纱紗纲綱纳納纵縱纶綸纷紛纸紙纹紋纺紡纽紐纾紓" +
+	"线線绀紺绁紲绂紱练練组組绅紳细細织織终終绉縐绊絆绋紼绌絀绍紹绎繹经經绑綁绒絨结結绔絝绕繞绗絎绘繪给給绚絢绛絳络絡绝絕绞絞统統绠綆" +
+	"绡綃绢絹绣繡绥綏绦絛继繼绨綈绩績绪緒绫綾续續绮綺绯緋绰綽绳繩维維绵綿绶綬绷繃绸綢绺綹绻綣综綜绽綻绾綰绿綠缀綴缁緇缂緙缃緗缄緘缅緬" +
+	"缆纜缇緹缈緲缉緝缋繢缌緦缍綞缎緞缏緶缑緱缒縋缓緩缔締缕縷编編缗緡缘緣缙縉缚縛缛縟缜縝缝縫缟縞缠纏缡縭缢縊缣縑缤繽缥縹缦縵缧縲缨纓" +
+	"缩縮缪繆缫繅缬纈缭繚缮繕缯繒缰韁缱繾缲繰缳繯缴繳饥飢饧餳饨飩饩餼饪飪饫飫饬飭饭飯饮飲饯餞饰飾饱飽饲飼饴飴饵餌饶饒饷餉饺餃饼餅饽餑" +
+	"饿餓馀餘馁餒馄餛馅餡馆館馈饋馊餿馋饞馍饃馏餾馐饈馑饉馒饅馔饌馕饢贝貝贞貞负負贡貢财財责責贤賢败敗账賬货貨质質贩販贪貪贫貧贬貶购購" +
+	"贮貯贯貫贰貳贱賤贲賁贳貰贴貼贵貴贶貺贷貸贸貿费費贺賀贻貽贼賊贽贄贾賈贿賄赀貲赁賃赂賂赃贓资資赅賅赆贐赇賕赈賑赉賚赊賒赋賦赌賭赍齎" +
+	"赎贖赏賞赐賜赓賡赔賠赕賧赖賴赘贅赙賻赚賺赛賽赜賾赝贗赞贊赠贈赡贍赢贏赣贛页頁顶頂顷頃项項顺順须須顼頊顽頑顾顧顿頓颀頎颁頒颂頌预預" +
+	"颅顱领領颇頗颈頸颉頡颊頰颌頜颍潁颏頦颐頤频頻颓頹颔頷颖穎颗顆题題颚顎颛顓颜顏额額颞顳颟顢颠顛颡顙颢顥颤顫颦顰颧顴门門闩閂闪閃闫閆" +
+	"闭閉问問闯闖闰閏闱闈闲閒闳閎间間闵閔闶閌闷悶闸閘闹鬧闺閨闻聞闼闥闽閩闾閭阀閥阁閣阂閡阃閫阄鬮阅閱阆閬阈閾阉閹阊閶阋鬩阌閿阍閽阎閻" +
+	"阏閼阐闡阑闌阒闃阔闊阕闋阖闔阗闐阙闕阚闞马馬驭馭驮馱驯馴驰馳驱驅驳駁驴驢驵駔驶駛驷駟驸駙驹駒驺騶驻駐驼駝驽駑驾駕驿驛骀駘骁驍骂罵" +
+	"骄驕骅驊骆駱骇駭骈駢骊驪骋騁验驗骏駿骐騏骑騎骓騅骖驂骗騙骘騭骚騷骛騖骜驁骝騮骞騫骟騸骠驃骡騾骢驄骣驏骤驟骥驥骧驤吗嗎妈媽玛瑪码碼" +
+	"蚂螞犸獁鸟鳥凫鳧鸠鳩鸡雞鸢鳶鸣鳴鸥鷗鸦鴉鸨鴇鸩鴆鸪鴣鸫鶇鸬鸕鸭鴨鸯鴦鸱鴟鸲鴝鸳鴛鸵鴕鸶鷥鸷鷙鸸鴯鸹鴰鸺鵂鸽鴿鸾鸞鸿鴻鹁鵓鹂鸝鹃鵑" +
+	"鹄鵠鹅鵝鹆鵒鹇鷴鹈鵜鹉鵡鹊鵲鹋鶓鹌鵪鹎鵯鹏鵬鹑鶉鹕鶘鹗鶚鹘鶻鹚鶿鹜鶩鹞鷂鹣鶼鹤鶴鹦鸚鹧鷓鹨鷚鹫鷲鹬鷸鹭鷺鹰鷹鹳鸛岛島捣搗袅裊枭梟" +
+	"鱼魚鱿魷鲁魯鲍鮑鲇鮎鲈鱸鲋鮒鲑鮭鲔鮪鲛鮫鲜鮮鲟鱘鲠鯁鲢鰱鲤鯉鲥鰣鲨鯊鲫鯽鲭鯖鲲鯤鲳鯧鲵鯢鲶鯰鲷鯛鲸鯨鲻鯔鳃鰓鳄鱷鳅鰍鳇鰉鳌鰲鳍鰭" +
+	"鳏鰥鳐鰩鳕鱈鳖鱉鳗鰻鳝鱔鳞鱗鳟鱒鲧鯀鲱鯡鲩鯇渔漁噜嚕橹櫓车車轧軋轨軌轩軒轫軔转轉轭軛轮輪软軟轰轟轱軲轲軻轳轤轴軸轵軹轶軼轸軫轹轢" +
+	"轺軺轻輕载載轼軾轾輊辁輇较較辂輅辄輒辅輔辆輛辇輦辈輩辉輝辊輥辋輞辍輟辎輜辏輳辐輻辑輯输輸辔轡辕轅辖轄辗輾辘轆辙轍辚轔库庫阵陣连連" +
+	"莲蓮涟漣裤褲挥揮浑渾晖暉荤葷军軍堑塹暂暫惭慚渐漸斩斬崭嶄錾鏨轿轎见見观觀规規觅覓视視觇覘览覽觉覺觊覬觋覡觌覿觎覦觏覯觐覲觑覷现現" +
+	"砚硯苋莧岘峴舰艦宽寬蚬蜆风風飒颯飓颶飕颼飘飄飙飆飚飈枫楓疯瘋岚嵐韦韋韧韌韩韓韪韙韬韜围圍违違伟偉苇葦炜煒玮瑋长長张張帐帳胀脹涨漲" +
+	"怅悵枨棖东東冻凍栋棟陈陳乐樂砾礫烁爍栎櫟爱愛碍礙袄襖罢罷摆擺办辦帮幫宝寶报報备備笔筆币幣毕畢毙斃边邊变變标標别別宾賓滨濱鬓鬢并並" +
+	"拨撥补補参參蚕蠶残殘惨慘灿燦苍蒼舱艙仓倉沧滄层層搀攙产產场場尝嘗偿償肠腸厂廠畅暢彻徹尘塵衬襯称稱惩懲痴癡迟遲齿齒炽熾冲衝虫蟲宠寵" +
+	"畴疇踌躊筹籌丑醜橱櫥厨廚雏雛础礎储儲触觸处處传傳疮瘡创創辞辭聪聰葱蔥从從丛叢凑湊窜竄达達带帶担擔单單胆膽惮憚弹彈当當挡擋党黨荡蕩" +
+	"档檔导導祷禱灯燈邓鄧敌敵籴糴递遞点點垫墊电電淀澱叠疊动動斗鬥犊犢独獨笃篤断斷队隊对對吨噸夺奪堕墮恶惡儿兒尔爾发發罚罰珐琺矾礬烦煩" +
+	"飞飛废廢坟墳奋奮愤憤粪糞丰豐冯馮凤鳳肤膚抚撫复復妇婦盖蓋干幹赶趕秆稈冈岡刚剛岗崗搁擱个個龚龔巩鞏沟溝构構蛊蠱关關惯慣广廣归歸龟龜" +
+	"柜櫃刽劊滚滾国國过過汉漢号號后後壶壺护護沪滬哗嘩华華画畫划劃怀懷坏壞欢歡环環还還秽穢会會烩燴汇匯获獲祸禍击擊机機积積极極挤擠几幾" +
+	"蓟薊剂劑济濟际際夹夾荚莢价價歼殲监監坚堅笺箋艰艱茧繭检檢碱鹼硷鹼拣揀捡撿简簡俭儉减減荐薦槛檻鉴鑒践踐剑劍溅濺涧澗将將浆漿蒋蔣桨槳" +
+	"奖獎酱醬胶膠浇澆娇嬌搅攪矫矯侥僥脚腳阶階节節杰傑洁潔届屆紧緊仅僅进進晋晉烬燼尽盡劲勁茎莖惊驚径徑痉痙竞競净淨厩廄旧舊举舉据據惧懼" +
+	"剧劇开開凯凱壳殼垦墾恳懇抠摳夸誇块塊侩儈矿礦旷曠况況亏虧岿巋窥窺溃潰扩擴蜡蠟腊臘莱萊来來蓝藍栏欄拦攔篮籃兰蘭澜瀾揽攬懒懶烂爛滥濫" +
+	"捞撈劳勞涝澇垒壘类類泪淚篱籬离離里裡礼禮丽麗厉厲励勵历歷沥瀝隶隸俩倆联聯怜憐帘簾敛斂脸臉恋戀炼煉粮糧凉涼两兩疗療辽遼猎獵临臨邻鄰" +
+	"凛凜龄齡灵靈岭嶺刘劉龙龍聋聾咙嚨笼籠垄壟拢攏陇隴楼樓娄婁搂摟篓簍芦蘆卢盧庐廬炉爐掳擄卤滷虏虜录錄陆陸吕呂侣侶屡屢虑慮滤濾峦巒挛攣" +
+	"孪孿滦灤乱亂抡掄伦倫仑侖沦淪萝蘿罗羅逻邏箩籮买買麦麥卖賣迈邁脉脈瞒瞞蛮蠻满滿猫貓么麼没沒们們梦夢眯瞇弥彌幂冪庙廟灭滅悯憫亩畝难難" +
+	"挠撓脑腦恼惱腻膩拟擬聂聶啮嚙宁寧拧擰狞獰柠檸聍聹农農浓濃侬儂哝噥疟瘧欧歐殴毆呕嘔沤漚盘盤庞龐喷噴苹蘋凭憑泼潑扑撲朴樸栖棲凄淒脐臍" +
+	"齐齊岂豈启啟气氣弃棄牵牽迁遷签簽潜潛浅淺枪槍呛嗆墙牆蔷薔强強抢搶桥橋乔喬侨僑翘翹窍竅窃竊亲親寝寢氢氫倾傾庆慶琼瓊穷窮趋趨区區躯軀" +
+	"龋齲权權劝勸却卻确確扰擾热熱荣榮润潤洒灑萨薩伞傘丧喪扫掃涩澀杀殺筛篩晒曬陕陝伤傷烧燒舍捨摄攝慑懾审審婶嬸肾腎渗滲声聲胜勝圣聖师師" +
+	"狮獅湿濕尸屍时時蚀蝕实實势勢适適释釋寿壽兽獸枢樞书書属屬术術树樹竖豎数數帅帥双雙硕碩丝絲耸聳怂慫擞擻苏蘇肃肅虽雖随隨岁歲孙孫损損" +
+	"笋筍琐瑣獭獺挞撻态態摊攤瘫癱滩灘坛壇叹嘆汤湯烫燙涛濤腾騰誊謄体體屉屜条條厅廳听聽烃烴头頭秃禿图圖涂塗团團椭橢洼窪袜襪弯彎湾灣万萬" +
+	"网網为為潍濰伪偽卫衛温溫稳穩瓮甕挝撾蜗蝸涡渦窝窩卧臥呜嗚乌烏无無芜蕪吴吳坞塢雾霧务務牺犧袭襲习習戏戲虾蝦峡峽侠俠狭狹厦廈吓嚇咸鹹" +
+	"衔銜显顯险險献獻县縣羡羨宪憲厢廂乡鄉响響萧蕭嚣囂晓曉啸嘯蝎蠍协協挟挾携攜胁脅写寫泻瀉衅釁兴興汹洶虚虛嘘噓叙敘悬懸选選癣癬学學勋勳" +
+	"寻尋逊遜压壓哑啞亚亞烟煙盐鹽严嚴艳艷厌厭彦彥杨楊扬揚疡瘍阳陽痒癢养養样樣窑窯尧堯遥遙药藥爷爺业業叶葉医醫遗遺仪儀蚁蟻艺藝亿億忆憶" +
+	"义義异異荫蔭阴陰隐隱樱櫻婴嬰应應莹瑩萤螢营營荧熒蝇蠅哟喲拥擁佣傭痈癰踊踴咏詠涌湧优優忧憂邮郵犹猶游遊舆輿娱娛与與屿嶼狱獄誉譽渊淵" +
+	"园園员員圆圓远遠愿願跃躍粤粵云雲郧鄖匀勻陨隕运運蕴蘊酝醞晕暈韵韻杂雜灾災攒攢脏髒凿鑿枣棗灶竈择擇泽澤斋齋债債毡氈盏盞栈棧战戰赵趙" +
+	"蛰蟄这這侦偵挣掙睁睜狰猙争爭帧幀郑鄭职職执執挚摯掷擲帜幟滞滯种種肿腫众眾诌謅皱皺昼晝猪豬烛燭瞩矚嘱囑筑築专專砖磚桩樁庄莊装裝妆妝" +
+	"壮壯状狀坠墜浊濁渍漬总總邹鄒着著于於余餘郁鬱迹跡坝壩嫔嬪抛拋挂掛掸撣掺摻摇搖撑撐撵攆昙曇横橫测測潇瀟玺璽踪蹤辩辯酿釀侧側册冊则則" +
+	"删刪刹剎厕廁呐吶啰囉喽嘍狈狽亵褻伫佇准準仆僕采採占佔荨蕁荞蕎莺鶯莴萵蒌蔞蓦驀蔼藹蕲蘄藓蘚跄蹌跞躒跷蹺跸蹕跹躚跻躋踬躓蹑躡蹒蹣蹿躥" +
+	"躏躪躜躦呗唄呙咼呖嚦咛嚀哒噠哓嘵哔嗶哕噦哙噲哜嚌唛嘜唠嘮唢嗩啧嘖啬嗇啭囀喾嚳嗫囁嘤嚶囵圇圹壙坂阪坜壢垅壠垆壚垩堊垭埡垲塏埘塒埙塤" +
+	"埚堝堇菫奁奩奂奐妩嫵妪嫗娅婭娆嬈娈孌娲媧娴嫻婵嬋媪媼嫒嬡嫱嬙宫宮尴尷屃屓屦屨岖嶇岙嶴峄嶧峤嶠峥崢崂嶗崃崍崄嶮嵘嶸嵝嶁巅巔巯巰帏幃" +
+	"帻幘帼幗幞襆庑廡庼廎廪廩弑弒弪弳彟彠徕徠忏懺忾愾怃憮怄慪怆愴怿懌恸慟恹懨恺愷恻惻恽惲悫愨悭慳悮悞惬愜惫憊愠慍愦憒慭憖懑懣懔懍戆戇" +
+	"戋戔戗戧戬戩户戶扪捫抟摶挜掗挢撟挦撏换換掴摑揿撳摅攄摈擯撄攖撷擷撸擼斓斕旸暘昵暱晔曄暧曖杆桿杠槓杩榪枞樅枥櫪枧梘柽檉栀梔栅柵栉櫛" +
+	"栊櫳栌櫨栾欒桠椏桡橈桢楨桤榿桦樺桧檜梼檮梾棶棂欞椁槨椟櫝椠槧椤欏榄欖榇櫬榈櫚榉櫸槚檟槟檳槠櫧橥櫫橼櫞檩檁欤歟殁歿殇殤殒殞殓殮殚殫" +
+	"殡殯毂轂毁毀氇氌氩氬氲氳沣灃沩溈泞濘泷瀧泸瀘泺濼泾涇浃浹浈湞浍澮浏瀏浐滻浒滸浔潯涞淶涠潿涣渙涤滌渌淥渎瀆渑澠渖瀋溆漵滗潷滟灧滠灄" +
+	"滢瀅滪澦潆瀠潋瀲潴瀦濑瀨濒瀕灏灝炀煬炖燉炝熗烨燁焕煥焖燜焘燾牍牘牦犛犷獷狯獪狲猻猃獫猕獼猡玀猬蝟玑璣玚瑒玱瑲珑瓏珰璫珲琿琏璉瑶瑤" +
+	"瑷璦璎瓔瓒瓚瓯甌疖癤疠癘疬癧疱皰痖瘂痨癆痪瘓痫癇瘅癉瘗瘞瘘瘻瘪癟瘾癮瘿癭癞癩癫癲皑皚皲皸盗盜眍瞘睑瞼睐睞瞆瞶矶磯砀碭砗硨砜碸砺礪" +
+	"砻礱硁硜硖硤硗磽硙磑硵磠碛磧碜磣祯禎禀稟禄祿禅禪税稅稣穌穑穡窦竇窭窶笕筧笾籩筚篳筝箏箓籙箦簀箧篋箨籜箪簞箫簫篑簣簖籪籁籟籼秈粜糶" +
+	"粝糲糁糝糇餱絷縶纟糹罂罌罴羆羁羈羟羥耢耮耧耬耻恥聩聵肮骯肴餚胧朧胨腖胪臚胫脛脍膾脓膿脔臠脱脫脶腡腭齶腼靦腽膃膑臏臜臢舣艤舻艫芈羋" +
+	"芗薌苁蓯苈藶苌萇苎苧茏蘢茑蔦茔塋茕煢荆荊荙薘荛蕘荜蓽荠薺荥滎荦犖荩藎荪蓀荬蕒荭葒荮葤莅蒞莳蒔莶薟莸蕕莼蓴蒇蕆蒉蕢蓠蘺蓣蕷蓥鎣蔂虆" +
+	"蔹蘞蔺藺蕰薀薮藪藁槁虬虯虮蟣虿蠆蚝蠔蛎蠣蛏蟶蛱蛺蛲蟯蛳螄蛴蠐蜕蛻蝈蟈蝉蟬蝼螻蝾蠑螀螿螨蟎蟏蠨衮袞袯襏裆襠裢褳裣襝裥襇褛褸褴襤觞觴" +
+	"觯觶讠訁谫譾豮豶赪赬趱趲趸躉跶躂踯躑蹰躕迩邇迳逕逦邐邝鄺邬鄔邺鄴郏郟郐鄶郓鄆郦酈郸鄲酦醱酽釅酾釃銮鑾陉陘陧隉雠讎雳靂霁霽霭靄靓靚" +
+	"静靜靥靨鞑韃鞒鞽鞯韉韫韞飨饗餍饜髅髏髋髖髌髕魇魘魉魎黄黃黉黌黡黶黩黷黪黲黾黽鼋黿鼍鼉鼗鞀鼹鼴齑齏龀齔龁齕龃齟龅齙龆齠龇齜龈齦龉齬" +
+	"龊齪龌齷龛龕辫辮内內兑兌悦悅"
+
+// _zhCharsT2S 繁体到简体时_zhChars反过来之外的对照，包括异体字和一简对多繁的情况
+const _zhCharsT2S = "" +
+	"裏里麼么爲为僞伪衆众峯峰羣群綫线牀床粧妆敎教啓启祕秘銹锈綉绣閑闲閒闲飢饥饑饥溼湿蹟迹跡迹臺台檯台颱台乾干幹干後后髮发麵面麪面係系" +
+	"繫系複复曆历鬆松隻只穫获鍾钟範范製制沖冲徵征穀谷儘尽捲卷錶表噹当彙汇夥伙罈坛週周矇蒙濛蒙懞蒙闢辟麯曲麴曲昇升甦苏嚮向兇凶鬚须縴纤" +
+	"禦御嶽岳摺折緻致註注薑姜瞭了佈布採采瀋沈睏困紮扎佔占噁恶籲吁併并臟脏糰团鬨哄迴回剋克黴霉瀰弥籤签韆千鞦秋朮术餵喂硃朱癥症藉借託托" +
+	"盪荡颳刮蔔卜闆板彆别姦奸慄栗齣出綵彩著着鬍胡衚胡衕同鷄鸡喫吃歎叹亙亘傢家纔才餘余鹼碱準准於于鹵卤裡里醃腌捨舍鐘钟須须髒脏臘腊遊游" +
+	"僕仆纖纤簽签鬥斗醜丑盡尽當当劃划匯汇幾几壇坛葉叶雲云鬱郁樸朴蘇苏鹹咸傭佣願愿莊庄嘗尝築筑惡恶並并團团煙烟彌弥寧宁灑洒術术塗涂種种" +
+	"滷卤屍尸據据蕩荡蟲虫廠厂價价別别豐丰傑杰蘋苹啟启確确勝胜適适體体窪洼網网縣县壓压湧涌衝冲復复歷历獲获發发"
+
+// _zhPhrasesS2T 简体到繁体的词组，以空格分隔
+const _zhPhrasesS2T = "" +
+	"头发:頭髮 理发:理髮 白发:白髮 毛发:毛髮 发型:髮型 染发:染髮 脱发:脫髮 黑发:黑髮 金发:金髮 长发:長髮 短发:短髮 卷发:捲髮 " +
+	"秀发:秀髮 鬓发:鬢髮 须发:鬚髮 发髻:髮髻 发丝:髮絲 发梢:髮梢 发际:髮際 发夹:髮夾 假发:假髮 削发:削髮 剃发:剃髮 披头散发:披頭散髮 " +
+	"怒发冲冠:怒髮衝冠 千钧一发:千鈞一髮 间不容发:間不容髮 落发:落髮 华发:華髮 发辫:髮辮 发饰:髮飾 发根:髮根 发廊:髮廊 束发:束髮 银发:銀髮 红发:紅髮 " +
+	"乱发:亂髮 发簪:髮簪 发带:髮帶 满头白发:滿頭白髮 头发现:頭發現 头发生:頭發生 头发出:頭發出 头发觉:頭發覺 头发动:頭發動 头发展:頭發展 白发现:白發現 干净:乾淨 " +
+	"干燥:乾燥 干旱:乾旱 饼干:餅乾 干杯:乾杯 干枯:乾枯 干瘪:乾癟 干涸:乾涸 干脆:乾脆 干粮:乾糧 干柴:乾柴 干草:乾草 干咳:乾咳 " +
+	"干笑:乾笑 干瘦:乾瘦 干爽:乾爽 干货:乾貨 晒干:曬乾 烘干:烘乾 擦干:擦乾 吹干:吹乾 风干:風乾 口干:口乾 干巴:乾巴 干爹:乾爹 " +
+	"干妈:乾媽 干娘:乾娘 干儿子:乾兒子 干女儿:乾女兒 干着急:乾著急 干瞪眼:乾瞪眼 干裂:乾裂 干冷:乾冷 外强中干:外強中乾 一干二净:一乾二淨 干硬:乾硬 干透:乾透 " +
+	"喝干:喝乾 吸干:吸乾 榨干:榨乾 干果:乾果 葡萄干:葡萄乾 干电池:乾電池 干洗:乾洗 肉干:肉乾 豆腐干:豆腐乾 干坤:乾坤 干隆:乾隆 干涉:干涉 " +
+	"干扰:干擾 干预:干預 若干:若干 相干:相干 干戈:干戈 干支:干支 天干:天干 干系:干係 干犯:干犯 干将:干將 干城:干城 皇后:皇后 " +
+	"太后:太后 王后:王后 后妃:后妃 影后:影后 天后:天后 母后:母后 后土:后土 后羿:后羿 后稷:后稷 歌后:歌后 后冠:后冠 皇太后:皇太后 " +
+	"公里:公里 英里:英里 千里:千里 万里:萬里 里程:里程 故里:故里 邻里:鄰里 乡里:鄉里 里长:里長 海里:海里 华里:華里 十里:十里 " +
+	"百里:百里 里弄:里弄 里巷:里巷 闾里:閭里 面条:麵條 面包:麵包 面粉:麵粉 拉面:拉麵 方便面:方便麵 面食:麵食 面馆:麵館 泡面:泡麵 " +
+	"汤面:湯麵 炒面:炒麵 凉面:涼麵 挂面:掛麵 面团:麵糰 一碗面:一碗麵 吃面:吃麵 下面条:下麵條 面筋:麵筋 台风:颱風 柜台:櫃檯 吧台:吧檯 " +
+	"台球:檯球 写字台:寫字檯 梳妆台:梳妝檯 台灯:檯燈 台面:檯面 关系:關係 没关系:沒關係 联系:聯繫 维系:維繫 系鞋带:繫鞋帶 系上:繫上 系好:繫好 " +
+	"系着:繫著 系住:繫住 系在:繫在 牵系:牽繫 系念:繫念 复杂:複雜 重复:重複 复制:複製 复印:複印 复数:複數 繁复:繁複 复合:複合 " +
+	"复式:複式 复习:複習 复述:複述 复眼:複眼 复姓:複姓 复写:複寫 日历:日曆 历法:曆法 农历:農曆 阳历:陽曆 阴历:陰曆 公历:公曆 " +
+	"挂历:掛曆 台历:檯曆 历书:曆書 皇历:皇曆 黄历:黃曆 放松:放鬆 松开:鬆開 轻松:輕鬆 宽松:寬鬆 松懈:鬆懈 松散:鬆散 蓬松:蓬鬆 " +
+	"松软:鬆軟 松动:鬆動 松手:鬆手 松了:鬆了 松口气:鬆口氣 松绑:鬆綁 稀松:稀鬆 松弛:鬆弛 松垮:鬆垮 松快:鬆快 松脱:鬆脫 肉松:肉鬆 " +
+	"一只:一隻 两只:兩隻 几只:幾隻 这只:這隻 那只:那隻 哪只:哪隻 每只:每隻 三只:三隻 四只:四隻 五只:五隻 只身:隻身 船只:船隻 " +
+	"只言片语:隻言片語 形单影只:形單影隻 一只手:一隻手 收获:收穫 钟情:鍾情 钟爱:鍾愛 一见钟情:一見鍾情 钟馗:鍾馗 钟灵毓秀:鍾靈毓秀 范围:範圍 规范:規範 示范:示範 " +
+	"模范:模範 范例:範例 范畴:範疇 防范:防範 典范:典範 风范:風範 范本:範本 范式:範式 就范:就範 制造:製造 制作:製作 制品:製品 " +
+	"制成:製成 复制品:複製品 绘制:繪製 炼制:煉製 研制:研製 配制:配製 缝制:縫製 精制:精製 监制:監製 特制:特製 印制:印製 摄制:攝製 " +
+	"录制:錄製 仿制:仿製 自制:自製 泡制:泡製 炮制:炮製 调制:調製 烹制:烹製 酿制:釀製 制药:製藥 制图:製圖 腌制:醃製 冲洗:沖洗 " +
+	"冲水:沖水 冲凉:沖涼 冲泡:沖泡 冲茶:沖茶 冲刷:沖刷 冲淡:沖淡 兴冲冲:興沖沖 冲喜:沖喜 冲印:沖印 冲澡:沖澡 冲积:沖積 冲天:沖天 " +
+	"怒气冲冲:怒氣沖沖 气冲冲:氣沖沖 特征:特徵 象征:象徵 征求:徵求 征收:徵收 征兆:徵兆 征集:徵集 征召:徵召 征税:徵稅 征文:徵文 征婚:徵婚 " +
+	"征询:徵詢 征聘:徵聘 表征:表徵 征候:徵候 征象:徵象 征信:徵信 北斗:北斗 斗笠:斗笠 漏斗:漏斗 烟斗:煙斗 熨斗:熨斗 车载斗量:車載斗量 " +
+	"斗胆:斗膽 斗篷:斗篷 星斗:星斗 斗室:斗室 一斗:一斗 筋斗:筋斗 斗转星移:斗轉星移 才高八斗:才高八斗 斗拱:斗拱 泰斗:泰斗 斗大:斗大 谷物:穀物 " +
+	"稻谷:稻穀 五谷:五穀 谷子:穀子 谷仓:穀倉 谷雨:穀雨 谷类:穀類 谷粒:穀粒 谷场:穀場 谷穗:穀穗 丑时:丑時 小丑:小丑 丑角:丑角 " +
+	"子丑:子丑 尽管:儘管 尽量:儘量 尽快:儘快 尽早:儘早 尽可能:儘可能 尽先:儘先 卷入:捲入 卷起:捲起 席卷:席捲 卷曲:捲曲 卷土重来:捲土重來 " +
+	"卷烟:捲煙 卷尺:捲尺 龙卷风:龍捲風 卷帘:捲簾 春卷:春捲 花卷:花捲 卷走:捲走 卷进:捲進 卷成:捲成 铺盖卷:鋪蓋捲 批准:批准 准许:准許 " +
+	"不准:不准 准予:准予 获准:獲准 恩准:恩准 核准:核准 准假:准假 手表:手錶 钟表:鐘錶 表带:錶帶 怀表:懷錶 秒表:秒錶 电表:電錶 " +
+	"水表:水錶 表盘:錶盤 金表:金錶 叮当:叮噹 当啷:噹啷 划船:划船 划算:划算 划桨:划槳 划不来:划不來 划拳:划拳 划水:划水 划得来:划得來 " +
+	"词汇:詞彙 汇编:彙編 字汇:字彙 汇总:彙總 汇整:彙整 伙伴:夥伴 伙计:夥計 团伙:團夥 合伙:合夥 入伙:入夥 一伙:一夥 同伙:同夥 " +
+	"大伙:大夥 家伙:傢伙 家具:傢俱 家私:傢俬 伙同:夥同 茶几:茶几 几案:几案 窗明几净:窗明几淨 酒坛:酒罈 坛子:罈子 人云亦云:人云亦云 诗云:詩云 " +
+	"云云:云云 不知所云:不知所云 子曰诗云:子曰詩云 浓郁:濃郁 馥郁:馥郁 周末:週末 周年:週年 周期:週期 周刊:週刊 周岁:週歲 一周:一週 上周:上週 " +
+	"下周:下週 本周:本週 每周:每週 周一:週一 周二:週二 周三:週三 周四:週四 周五:週五 周六:週六 周日:週日 周报:週報 两周:兩週 " +
+	"几周:幾週 蒙骗:矇騙 迷蒙:迷濛 蒙蒙:濛濛 空蒙:空濛 开辟:開闢 精辟:精闢 辟谣:闢謠 另辟蹊径:另闢蹊徑 开天辟地:開天闢地 辟出:闢出 前仆后继:前仆後繼 " +
+	"酒曲:酒麴 宿舍:宿舍 舍下:舍下 寒舍:寒舍 旅舍:旅舍 校舍:校舍 房舍:房舍 农舍:農舍 舍弟:舍弟 退避三舍:退避三舍 左邻右舍:左鄰右舍 舍利:舍利 " +
+	"鸡舍:雞舍 猪舍:豬舍 茅舍:茅舍 精舍:精舍 客舍:客舍 屋舍:屋舍 苏醒:甦醒 复苏:復甦 向导:嚮導 向往:嚮往 凶手:兇手 凶恶:兇惡 " +
+	"凶狠:兇狠 凶残:兇殘 凶器:兇器 帮凶:幫兇 行凶:行兇 凶猛:兇猛 凶悍:兇悍 凶杀:兇殺 凶犯:兇犯 真凶:真兇 元凶:元兇 逞凶:逞兇 " +
+	"凶神恶煞:兇神惡煞 胡须:鬍鬚 胡子:鬍子 胡茬:鬍茬 络腮胡:絡腮鬍 胡同:衚衕 八字胡:八字鬍 山羊胡:山羊鬍 大胡子:大鬍子 咸丰:咸豐 咸阳:咸陽 老少咸宜:老少咸宜 " +
+	"纤夫:縴夫 拉纤:拉縴 佣金:佣金 佣钱:佣錢 游泳:游泳 游水:游水 上游:上游 下游:下游 中游:中游 力争上游:力爭上游 游鱼:游魚 游过:游過 " +
+	"游向:游向 游回:游回 防御:防禦 抵御:抵禦 御寒:禦寒 御敌:禦敵 五岳:五嶽 山岳:山嶽 东岳:東嶽 西岳:西嶽 南岳:南嶽 北岳:北嶽 " +
+	"中岳:中嶽 折叠:摺疊 折扇:摺扇 折子:摺子 奏折:奏摺 折纸:摺紙 精致:精緻 细致:細緻 别致:別緻 雅致:雅緻 标致:標緻 致密:緻密 " +
+	"景致:景緻 工致:工緻 注册:註冊 注释:註釋 注解:註解 批注:批註 附注:附註 注脚:註腳 备注:備註 注明:註明 脚注:腳註 注销:註銷 " +
+	"标注:標註 生姜:生薑 姜汤:薑湯 老姜:老薑 姜丝:薑絲 姜片:薑片 姜末:薑末 姜茶:薑茶 了望:瞭望 布置:佈置 布局:佈局 遍布:遍佈 " +
+	"密布:密佈 布满:佈滿 散布:散佈 摆布:擺佈 布告:佈告 布防:佈防 布阵:佈陣 布下:佈下 神采:神采 风采:風采 文采:文采 兴高采烈:興高采烈 " +
+	"无精打采:無精打采 丰采:丰采 采邑:采邑 光采:光采 沈阳:瀋陽 犯困:犯睏 扎营:紮營 驻扎:駐紮 包扎:包紮 扎根:紮根 扎实:紮實 扎辫子:紮辮子 " +
+	"安营扎寨:安營紮寨 扎染:紮染 占卜:占卜 占卦:占卦 占星:占星 占梦:占夢 占课:占課 恶心:噁心 呼吁:呼籲 吁请:籲請 合并:合併 吞并:吞併 " +
+	"兼并:兼併 并购:併購 并入:併入 归并:歸併 并发症:併發症 心脏:心臟 内脏:內臟 肝脏:肝臟 肾脏:腎臟 脏腑:臟腑 脾脏:脾臟 肺脏:肺臟 " +
+	"五脏:五臟 脏器:臟器 五脏六腑:五臟六腑 饭团:飯糰 汤团:湯糰 起哄:起鬨 一哄而散:一鬨而散 回旋:迴旋 回避:迴避 回响:迴響 轮回:輪迴 迂回:迂迴 " +
+	"回廊:迴廊 回荡:迴盪 回肠荡气:迴腸盪氣 巡回:巡迴 回纹针:迴紋針 峰回路转:峰迴路轉 回环:迴環 克扣:剋扣 克星:剋星 相克:相剋 生克:生剋 克死:剋死 " +
+	"发霉:發黴 霉菌:黴菌 霉变:黴變 霉烂:黴爛 霉素:黴素 弥漫:瀰漫 抽签:抽籤 书签:書籤 标签:標籤 求签:求籤 牙签:牙籤 签子:籤子 " +
+	"竹签:竹籤 中签:中籤 秋千:鞦韆 荡秋千:盪鞦韆 白术:白朮 苍术:蒼朮 喂养:餵養 喂奶:餵奶 喂食:餵食 喂饱:餵飽 喂马:餵馬 喂猪:餵豬 " +
+	"喂药:餵藥 喂鸡:餵雞 朱砂:硃砂 卤素:鹵素 卤族:鹵族 尸位素餐:尸位素餐 症结:癥結 借口:藉口 凭借:憑藉 借助:藉助 借故:藉故 借机:藉機 " +
+	"借以:藉以 借此:藉此 拮据:拮据 委托:委託 托付:託付 拜托:拜託 寄托:寄託 推托:推託 托词:託詞 信托:信託 托人:託人 托福:託福 " +
+	"嘱托:囑託 假托:假託 托梦:託夢 托孤:託孤 重托:重託 托管:託管 荡漾:盪漾 动荡:動盪 震荡:震盪 摇荡:搖盪 飘荡:飄盪 激荡:激盪 " +
+	"涤荡:滌盪 荡涤:盪滌 刮风:颳風 风刮:風颳 刮起风:颳起風 刮大风:颳大風 萝卜:蘿蔔 胡萝卜:胡蘿蔔 老板:老闆 别扭:彆扭 强奸:強姦 通奸:通姦 " +
+	"奸淫:姦淫 奸污:姦污 奸夫:姦夫 奸情:姦情 捉奸:捉姦 轮奸:輪姦 战栗:戰慄 不寒而栗:不寒而慄 一出戏:一齣戲 剪彩:剪綵 张灯结彩:張燈結綵"
+
+// _zhPhrasesT2S 繁体到简体的词组，用于逐字转换会出错的词
+const _zhPhrasesT2S = "" +
+	"乾隆:乾隆 乾坤:乾坤 乾卦:乾卦 乾清宮:乾清宫 乾元:乾元 著作:著作 著名:著名 顯著:显著 著稱:著称 名著:名著 原著:原著 巨著:巨著 " +
+	"專著:专著 論著:论著 編著:编著 土著:土著 遺著:遗著 合著:合著 著述:著述 卓著:卓著 昭著:昭著 著者:著者 撰著:撰著 著錄:著录 " +
+	"拙著:拙著 新著:新著 大著:大著 譯著:译著 著書:著书 臭名昭著:臭名昭著 著書立說:著书立说 狼藉:狼藉 慰藉:慰藉 蘊藉:蕴藉 枕藉:枕藉 聲名狼藉:声名狼藉 " +
+	"瞭望:瞭望 宮商角徵羽:宫商角徵羽 傢俱:家具 傢具:家具 反覆:反复 答覆:答复 回覆:回复 傢俬:家私"